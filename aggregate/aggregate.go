@@ -0,0 +1,327 @@
+// Package aggregate groups errors by fingerprint in memory, so a service
+// without an external error tracker can still answer "what are my top errors in
+// the last hour".
+//
+//	agg := aggregate.New(aggregate.WithWindow(time.Hour))
+//
+//	agg.Record(err)         // wherever errors are handled or logged
+//	errx.OnCreate(agg.Hook) // or as errors are created
+//
+//	for _, g := range agg.Top(10) {
+//	    fmt.Println(g.WindowCount, g.Code, g.Message)
+//	}
+//
+// Errors are grouped by errx.Fingerprint, so occurrences of the same failure are
+// counted together even when their messages contain IDs. Each group tracks its
+// total count, first and last occurrence, the codes it was reported with, the
+// redacted metadata of its latest occurrence, and its count over a sliding
+// window. WriteJSON renders a report for debug endpoints.
+package aggregate
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// Default aggregator settings.
+const (
+	DefaultWindow    = time.Hour
+	DefaultBucket    = time.Minute
+	DefaultMaxGroups = 1000
+)
+
+// Option configures an Aggregator.
+type Option func(*Aggregator)
+
+// WithWindow sets the length of the sliding window over which WindowCount is
+// computed. Values below the bucket size are treated as one bucket.
+func WithWindow(d time.Duration) Option {
+	return func(a *Aggregator) {
+		a.window = d
+	}
+}
+
+// WithBucket sets the resolution of the sliding window. Smaller buckets make
+// window counts more precise at the cost of memory per group.
+func WithBucket(d time.Duration) Option {
+	return func(a *Aggregator) {
+		a.bucket = d
+	}
+}
+
+// WithMaxGroups bounds the number of groups kept. When a new group would exceed
+// it, the group seen least recently is evicted. Values below 1 are treated as 1.
+func WithMaxGroups(n int) Option {
+	return func(a *Aggregator) {
+		a.maxGroups = max(n, 1)
+	}
+}
+
+// WithFingerprintOptions sets the options passed to errx.Fingerprint.
+func WithFingerprintOptions(opts ...errx.FingerprintOption) Option {
+	return func(a *Aggregator) {
+		a.fingerprintOpts = opts
+	}
+}
+
+// WithClock sets the time source, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(a *Aggregator) {
+		a.now = now
+	}
+}
+
+// Group summarizes the occurrences of one failure.
+type Group struct {
+	Fingerprint string         `json:"fingerprint"`
+	Code        string         `json:"code"`             // Code of the originating error
+	Reason      string         `json:"reason,omitempty"` // Reason of the originating error
+	Source      string         `json:"source,omitempty"` // Source of the originating error
+	Message     string         `json:"message"`          // Message template, or message, of the originating error
+	Codes       map[string]int `json:"codes"`            // Occurrences by the code of the recorded error
+	Count       int            `json:"count"`            // Occurrences since FirstSeen
+	WindowCount int            `json:"window_count"`     // Occurrences within the window
+	FirstSeen   time.Time      `json:"first_seen"`
+	LastSeen    time.Time      `json:"last_seen"`
+	LastID      string         `json:"last_id,omitempty"`  // Instance ID of the latest occurrence
+	Metadata    map[string]any `json:"metadata,omitempty"` // Redacted metadata of the latest occurrence
+}
+
+// Report is the JSON document written by WriteJSON.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Window      string    `json:"window"`
+	Groups      []Group   `json:"groups"`
+}
+
+// group is the mutable state behind a Group.
+type group struct {
+	Group
+	buckets []bucket // ring of per-bucket counts
+}
+
+// bucket counts occurrences in one bucket-sized interval.
+type bucket struct {
+	index int64 // interval number since the Unix epoch
+	count int
+}
+
+// Aggregator groups recorded errors by fingerprint. It is safe for concurrent use.
+type Aggregator struct {
+	window          time.Duration
+	bucket          time.Duration
+	maxGroups       int
+	fingerprintOpts []errx.FingerprintOption
+	now             func() time.Time
+
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// New creates an Aggregator.
+func New(opts ...Option) *Aggregator {
+	a := &Aggregator{
+		window:    DefaultWindow,
+		bucket:    DefaultBucket,
+		maxGroups: DefaultMaxGroups,
+		now:       time.Now,
+		groups:    make(map[string]*group),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.bucket <= 0 {
+		a.bucket = DefaultBucket
+	}
+	return a
+}
+
+// buckets returns the number of buckets in the window.
+func (a *Aggregator) buckets() int {
+	return max(int(a.window/a.bucket), 1)
+}
+
+// Record counts an occurrence of err. Nil errors are ignored.
+func (a *Aggregator) Record(err error) {
+	if err == nil {
+		return
+	}
+	fingerprint := errx.Fingerprint(err, a.fingerprintOpts...)
+	now := a.now()
+	index := now.UnixNano() / int64(a.bucket)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, ok := a.groups[fingerprint]
+	if !ok {
+		if len(a.groups) >= a.maxGroups {
+			a.evict()
+		}
+		g = newGroup(fingerprint, err, a.buckets())
+		g.FirstSeen = now
+		a.groups[fingerprint] = g
+	}
+
+	g.Count++
+	g.Codes[errx.CodeOf(err).String()]++
+	g.LastSeen = now
+	g.LastID = ""
+	g.Metadata = nil
+	if e, ok := errx.As(err); ok {
+		g.LastID = e.ID()
+		if md := e.Metadata(); len(md) > 0 {
+			g.Metadata = errx.DefaultRedactor().Map(maps.Clone(md))
+		}
+	}
+
+	b := &g.buckets[index%int64(len(g.buckets))]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.count++
+}
+
+// Hook records errors as they are created. Register it with errx.OnCreate:
+//
+//	errx.OnCreate(agg.Hook)
+//
+// Only originating errors are recorded; errors wrapping another *Error were
+// counted when their cause was created. Since creation hooks run before methods
+// such as WithSource or WithMeta, groups are keyed on the fields set at creation
+// and carry no metadata, so their fingerprints can differ from those of handled
+// errors. To record errors with all their fields, record them once handled:
+//
+//	errx.OnCheck(func(e *errx.Error) { agg.Record(e) })
+func (a *Aggregator) Hook(e *errx.Error) {
+	if _, ok := errx.As(e.Unwrap()); ok {
+		return
+	}
+	a.Record(e)
+}
+
+// newGroup creates the group for the failure behind err.
+// Messages are redacted, since they can contain data interpolated by the caller.
+func newGroup(fingerprint string, err error, buckets int) *group {
+	red := errx.DefaultRedactor()
+	g := &group{
+		Group: Group{
+			Fingerprint: fingerprint,
+			Code:        errx.CodeUnknown.String(),
+			Message:     red.Message(err),
+			Codes:       make(map[string]int),
+		},
+		buckets: make([]bucket, buckets),
+	}
+	if e, ok := origin(err); ok {
+		g.Code = e.Code().String()
+		g.Reason = e.Reason()
+		g.Source = e.Source()
+		g.Message = cmp.Or(e.Template(), red.Message(e))
+	}
+	return g
+}
+
+// origin returns the innermost *Error in err's chain, the one fingerprinted by
+// errx.Fingerprint.
+func origin(err error) (*errx.Error, bool) {
+	e, ok := errx.As(err)
+	if !ok {
+		return nil, false
+	}
+	for {
+		cause, ok := errx.As(e.Unwrap())
+		if !ok {
+			return e, true
+		}
+		e = cause
+	}
+}
+
+// evict removes the group seen least recently. The caller must hold a.mu.
+func (a *Aggregator) evict() {
+	var oldest *group
+	for _, g := range a.groups {
+		if oldest == nil || g.LastSeen.Before(oldest.LastSeen) {
+			oldest = g
+		}
+	}
+	if oldest != nil {
+		delete(a.groups, oldest.Fingerprint)
+	}
+}
+
+// Snapshot returns every group, ordered by WindowCount, then Count, then
+// LastSeen, all descending.
+func (a *Aggregator) Snapshot() []Group {
+	index := a.now().UnixNano() / int64(a.bucket)
+
+	a.mu.Lock()
+	groups := make([]Group, 0, len(a.groups))
+	for _, g := range a.groups {
+		s := g.Group
+		s.Codes = maps.Clone(g.Codes)
+		s.Metadata = maps.Clone(g.Metadata)
+		s.WindowCount = 0
+		for _, b := range g.buckets {
+			if b.index > index-int64(len(g.buckets)) && b.index <= index {
+				s.WindowCount += b.count
+			}
+		}
+		groups = append(groups, s)
+	}
+	a.mu.Unlock()
+
+	slices.SortFunc(groups, func(x, y Group) int {
+		return cmp.Or(
+			cmp.Compare(y.WindowCount, x.WindowCount),
+			cmp.Compare(y.Count, x.Count),
+			y.LastSeen.Compare(x.LastSeen),
+			cmp.Compare(x.Fingerprint, y.Fingerprint),
+		)
+	})
+	return groups
+}
+
+// Top returns up to n groups with occurrences in the window, most frequent
+// first. A non-positive n returns all of them.
+func (a *Aggregator) Top(n int) []Group {
+	groups := a.Snapshot()
+	i := slices.IndexFunc(groups, func(g Group) bool { return g.WindowCount == 0 })
+	if i >= 0 {
+		groups = groups[:i]
+	}
+	if n > 0 && len(groups) > n {
+		groups = groups[:n]
+	}
+	return groups
+}
+
+// Reset removes every group.
+func (a *Aggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	clear(a.groups)
+}
+
+// WriteJSON writes a Report of the top n groups in the window to w; see Top.
+func (a *Aggregator) WriteJSON(w io.Writer, n int) error {
+	report := Report{
+		GeneratedAt: a.now().UTC(),
+		Window:      a.window.String(),
+		Groups:      a.Top(n),
+	}
+	if report.Groups == nil {
+		report.Groups = []Group{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
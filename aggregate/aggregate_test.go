@@ -0,0 +1,212 @@
+package aggregate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/aggregate"
+)
+
+type aggregateSuite struct {
+	suite.Suite
+	now time.Time
+	agg *aggregate.Aggregator
+}
+
+func TestAggregateSuite(t *testing.T) {
+	suite.Run(t, new(aggregateSuite))
+}
+
+func (s *aggregateSuite) SetupTest() {
+	s.now = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	s.agg = aggregate.New(
+		aggregate.WithWindow(time.Hour),
+		aggregate.WithBucket(time.Minute),
+		aggregate.WithClock(func() time.Time { return s.now }),
+	)
+}
+
+func userNotFound(id string) error {
+	return errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").
+		WithSource("users").
+		WithDetail("user_id", id).
+		WithMeta("user_id", id)
+}
+
+func dbDown() error {
+	return errx.WrapInternal(errors.New("connection refused"), "database unavailable")
+}
+
+func (s *aggregateSuite) TestGroupsByFingerprint() {
+	for i := range 3 {
+		s.agg.Record(userNotFound(fmt.Sprint("u-", i)))
+	}
+	s.agg.Record(dbDown())
+	s.agg.Record(nil)
+
+	groups := s.agg.Snapshot()
+	s.Require().Len(groups, 2)
+
+	g := groups[0]
+	s.Len(g.Fingerprint, 16)
+	s.Equal("not_found", g.Code)
+	s.Equal("users", g.Source)
+	s.Equal("user {user_id} not found", g.Message)
+	s.Equal(3, g.Count)
+	s.Equal(3, g.WindowCount)
+	s.Equal(map[string]int{"not_found": 3}, g.Codes)
+	s.Equal(map[string]any{"user_id": "u-2"}, g.Metadata)
+	s.Equal(s.now, g.FirstSeen)
+
+	s.Equal("database unavailable", groups[1].Message)
+	s.Equal(1, groups[1].Count)
+}
+
+func (s *aggregateSuite) TestOriginatingErrorAndCodes() {
+	for _, code := range []errx.Code{errx.CodeUnavailable, errx.CodeInternal, errx.CodeUnavailable} {
+		s.agg.Record(errx.Wrap(userNotFound("u-1"), code, "lookup failed"))
+	}
+
+	groups := s.agg.Snapshot()
+	s.Require().Len(groups, 1)
+	s.Equal("not_found", groups[0].Code)
+	s.Equal(map[string]int{"unavailable": 2, "internal": 1}, groups[0].Codes)
+}
+
+func (s *aggregateSuite) TestSlidingWindow() {
+	// Record from one call site, so both occurrences share a fingerprint.
+	start := s.now
+	for _, d := range []time.Duration{0, 30 * time.Minute} {
+		s.now = s.now.Add(d)
+		s.agg.Record(dbDown())
+	}
+	s.Equal(2, s.agg.Snapshot()[0].WindowCount)
+
+	s.now = s.now.Add(45 * time.Minute)
+	g := s.agg.Snapshot()[0]
+	s.Equal(1, g.WindowCount)
+	s.Equal(2, g.Count)
+	s.Equal(start, g.FirstSeen)
+	s.Equal(start.Add(30*time.Minute), g.LastSeen)
+
+	s.now = s.now.Add(time.Hour)
+	s.Equal(0, s.agg.Snapshot()[0].WindowCount)
+	s.Empty(s.agg.Top(10))
+}
+
+func (s *aggregateSuite) TestTop() {
+	for range 3 {
+		s.agg.Record(dbDown())
+	}
+	s.agg.Record(userNotFound("u-1"))
+
+	top := s.agg.Top(1)
+	s.Require().Len(top, 1)
+	s.Equal("database unavailable", top[0].Message)
+	s.Len(s.agg.Top(0), 2)
+}
+
+func (s *aggregateSuite) TestMaxGroupsEvictsLeastRecent() {
+	agg := aggregate.New(
+		aggregate.WithMaxGroups(2),
+		aggregate.WithFingerprintOptions(errx.WithFingerprintFrames(0)),
+		aggregate.WithClock(func() time.Time { return s.now }),
+	)
+	for i := range 3 {
+		agg.Record(errx.NewInternal(fmt.Sprint("failure ", i)))
+		s.now = s.now.Add(time.Second)
+	}
+
+	var messages []string
+	for _, g := range agg.Snapshot() {
+		messages = append(messages, g.Message)
+	}
+	s.ElementsMatch([]string{"failure 1", "failure 2"}, messages)
+}
+
+func (s *aggregateSuite) TestRedactsMetadata() {
+	s.agg.Record(errx.NewUnauthenticated("login failed").WithMeta("password", "hunter2"))
+	s.Equal(errx.RedactedText, s.agg.Snapshot()[0].Metadata["password"])
+}
+
+func (s *aggregateSuite) TestPlainErrors() {
+	s.agg.Record(errors.New("boom"))
+	g := s.agg.Snapshot()[0]
+	s.Equal("unknown", g.Code)
+	s.Equal("boom", g.Message)
+}
+
+func (s *aggregateSuite) TestWriteJSON() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.agg.WriteJSON(&buf, 10))
+	s.Contains(buf.String(), `"groups": []`)
+
+	s.agg.Record(dbDown())
+	buf.Reset()
+	s.Require().NoError(s.agg.WriteJSON(&buf, 10))
+
+	var report aggregate.Report
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &report))
+	s.Equal("1h0m0s", report.Window)
+	s.Equal(s.now, report.GeneratedAt)
+	s.Require().Len(report.Groups, 1)
+	s.Equal(1, report.Groups[0].WindowCount)
+}
+
+func (s *aggregateSuite) TestReset() {
+	s.agg.Record(dbDown())
+	s.agg.Reset()
+	s.Empty(s.agg.Snapshot())
+}
+
+func (s *aggregateSuite) TestConcurrentRecord() {
+	agg := aggregate.New()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				agg.Record(dbDown())
+			}
+		}()
+	}
+	wg.Wait()
+
+	groups := agg.Snapshot()
+	s.Require().Len(groups, 1)
+	s.Equal(800, groups[0].Count)
+}
+
+func (s *aggregateSuite) TestHook() {
+	defer errx.OnCreate(s.agg.Hook)()
+
+	for range 2 {
+		inner := errx.NewNotFound("user not found")
+		_ = errx.Wrap(inner, errx.CodeInternal, "lookup failed")
+	}
+
+	groups := s.agg.Snapshot()
+	s.Require().Len(groups, 1, "wrapping errors are not counted again")
+	s.Equal("not_found", groups[0].Code)
+	s.Equal(2, groups[0].Count)
+}
+
+func (s *aggregateSuite) TestRedactsMessage() {
+	s.agg.Record(errors.New("no account for alice@example.com"))
+	s.agg.Record(errx.NewfNotFound("user %s not found", "bob@example.com"))
+
+	groups := s.agg.Snapshot()
+	s.Require().Len(groups, 2)
+	for _, g := range groups {
+		s.NotContains(g.Message, "@example.com")
+	}
+}
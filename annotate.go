@@ -0,0 +1,86 @@
+package errx
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// AnnotateOption adds information to the copy of an error made by Annotate.
+// Any function modifying an *Error, such as one calling its With methods, can be
+// used as an option.
+type AnnotateOption func(*Error)
+
+// WithMeta adds internal debug metadata, as (*Error).WithMeta does.
+func WithMeta(key string, value any) AnnotateOption {
+	return func(e *Error) {
+		e.WithMeta(key, value)
+	}
+}
+
+// WithMetaFrom adds the metadata stored in ctx, as (*Error).WithMetaFromContext
+// does.
+func WithMetaFrom(ctx context.Context) AnnotateOption {
+	return func(e *Error) {
+		e.WithMetaFromContext(ctx)
+	}
+}
+
+// WithDetail adds a client-safe detail, as (*Error).WithDetail does.
+func WithDetail(key string, value any) AnnotateOption {
+	return func(e *Error) {
+		e.WithDetail(key, value)
+	}
+}
+
+// WithTags adds tags, as (*Error).WithTags does.
+func WithTags(tags ...string) AnnotateOption {
+	return func(e *Error) {
+		e.WithTags(tags...)
+	}
+}
+
+// Annotate enriches an error returned by a lower layer without wrapping it, so
+// the message is not repeated and no stack trace is captured:
+//
+//	order, err := s.repo.Get(ctx, id)
+//	if err != nil {
+//	    return nil, errx.Annotate(err, errx.WithMeta("order_id", id), errx.WithTags("orders"))
+//	}
+//
+// If err is or wraps an *Error, Annotate returns a shallow copy of the nearest
+// one with the options applied. The original is never modified, so annotating
+// a shared error, such as a package-level sentinel, is safe. Like Ensure, it
+// returns that *Error rather than err, dropping non-errx wrappers around it.
+// The copy keeps the original's cause, stack trace, ID and creation time.
+//
+// Otherwise, err is wrapped with code unknown and the message "unknown error",
+// as Ensure would, and the options are applied to the wrapper. Annotate returns
+// nil for a nil error.
+func Annotate(err error, opts ...AnnotateOption) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if found, ok := As(err); ok {
+		e = found.derive()
+	} else {
+		e = newError(CodeUnknown, "unknown error", err)
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// derive returns a copy of e that can be modified without affecting e: the
+// slices and maps that With methods append to or write are cloned.
+func (e *Error) derive() *Error {
+	c := *e
+	c.tags = slices.Clip(e.tags)
+	c.ops = slices.Clip(e.ops)
+	c.details = maps.Clone(e.details)
+	c.metadata = maps.Clone(e.metadata)
+	c.messageParams = maps.Clone(e.messageParams)
+	return &c
+}
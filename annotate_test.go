@@ -0,0 +1,110 @@
+package errx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type annotateSuite struct {
+	suite.Suite
+}
+
+func TestAnnotateSuite(t *testing.T) {
+	suite.Run(t, new(annotateSuite))
+}
+
+func (s *annotateSuite) TestAddsWithoutWrapping() {
+	cause := errors.New("no rows")
+	orig := errx.WrapNotFound(cause, "order not found").WithMeta("table", "orders")
+
+	e := errx.Annotate(orig,
+		errx.WithMeta("order_id", 7),
+		errx.WithDetail("order_id", "o-7"),
+		errx.WithTags("orders"),
+	)
+
+	s.Equal(errx.CodeNotFound, e.Code())
+	s.Equal("order not found", e.Error())
+	s.Equal(cause, e.Unwrap(), "Annotate must not add a wrapper")
+	s.Equal(map[string]any{"table": "orders", "order_id": 7}, e.Metadata())
+	s.Equal(map[string]any{"order_id": "o-7"}, e.Details())
+	s.Equal([]string{"orders"}, e.Tags())
+	s.Equal(orig.StackTrace(), e.StackTrace())
+	s.Equal(orig.ID(), e.ID())
+	s.Equal(orig.Time(), e.Time())
+	s.ErrorIs(e, cause)
+	s.ErrorIs(e, errx.NewNotFound("any"))
+}
+
+func (s *annotateSuite) TestDoesNotMutateOriginal() {
+	orig := errx.NewNotFound("missing").
+		WithReason("ORDER_MISSING").
+		WithMeta("table", "orders").
+		WithDetail("kind", "order").
+		WithTags("db").
+		WithOp("repo.Get")
+	debug := orig.DebugMessage()
+
+	a := errx.Annotate(orig,
+		errx.WithMeta("table", "archive"),
+		errx.WithDetail("kind", "archived_order"),
+		errx.WithTags("archive"),
+		func(e *errx.Error) { e.WithSource("svc").WithOp("svc.Get") },
+	)
+	b := errx.Annotate(orig, errx.WithTags("other"))
+
+	s.Equal(debug, orig.DebugMessage())
+	s.Equal([]string{"db", "archive"}, a.Tags())
+	s.Equal([]string{"db", "other"}, b.Tags())
+	s.Equal([]string{"repo.Get", "svc.Get"}, a.Ops())
+	s.Equal([]string{"repo.Get"}, b.Ops())
+	s.Equal("svc", a.Source())
+	s.Empty(b.Source())
+	s.Equal("archive", a.Metadata()["table"])
+	s.Equal("orders", b.Metadata()["table"])
+	s.Equal("ORDER_MISSING", a.Reason())
+}
+
+func (s *annotateSuite) TestSharedSentinel() {
+	sentinel := errx.NewFailedPrecondition("invite has expired").WithReason("INVITE_EXPIRED")
+
+	e := errx.Annotate(sentinel, errx.WithMeta("invite_id", "i-1"))
+	s.ErrorIs(e, sentinel)
+	s.Empty(sentinel.Metadata())
+}
+
+func (s *annotateSuite) TestFindsNearestError() {
+	inner := errx.NewUnavailable("db down")
+	e := errx.Annotate(fmt.Errorf("query: %w", inner), errx.WithMeta("attempt", 2))
+
+	s.Equal(errx.CodeUnavailable, e.Code())
+	s.Equal(2, e.Metadata()["attempt"])
+	s.Empty(inner.Metadata())
+}
+
+func (s *annotateSuite) TestPlainError() {
+	cause := errors.New("connection refused")
+	e := errx.Annotate(cause, errx.WithMeta("host", "db-1"))
+
+	s.Equal(errx.CodeUnknown, e.Code())
+	s.Equal("unknown error", e.Error())
+	s.ErrorIs(e, cause)
+	s.Equal("db-1", e.Metadata()["host"])
+	s.NotEmpty(e.StackTrace())
+}
+
+func (s *annotateSuite) TestMetaFromContext() {
+	ctx := errx.WithMetaContext(context.Background(), "request_id", "r-1")
+	e := errx.Annotate(errx.NewInternal("boom"), errx.WithMetaFrom(ctx))
+	s.Equal("r-1", e.Metadata()["request_id"])
+}
+
+func (s *annotateSuite) TestNil() {
+	s.Nil(errx.Annotate(nil, errx.WithMeta("k", "v")))
+}
@@ -0,0 +1,320 @@
+// Package breaker provides a circuit breaker that decides what counts as a
+// failure from the errx code of an error.
+//
+// By default only server-side failures trip the breaker: errx errors with code
+// unavailable, deadline_exceeded, internal or resource_exhausted, and errors
+// errx knows nothing about, such as dial failures and context.DeadlineExceeded.
+// Client errors such as invalid_argument or not_found, and context.Canceled,
+// are passed through without affecting its state.
+//
+//	b := breaker.New(breaker.WithFailureThreshold(5), breaker.WithOpenTimeout(30*time.Second))
+//
+//	err := b.Do(ctx, func(ctx context.Context) error {
+//	    return client.Charge(ctx, req)
+//	})
+//	if errx.CodeIs(err, errx.CodeUnavailable) && errx.IsRetryable(err) {
+//	    // the breaker is open, or the call itself was unavailable
+//	}
+//
+// When open, the breaker rejects calls with an errx unavailable error that is
+// retryable and carries the time remaining until the next trial call as its retry
+// delay hint.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// State is the state of a circuit breaker.
+type State uint8
+
+// Breaker states.
+const (
+	StateClosed   State = iota // Calls are allowed and failures are counted
+	StateOpen                  // Calls are rejected until the open timeout elapses
+	StateHalfOpen              // A limited number of trial calls are allowed
+)
+
+// String implements the Stringer interface.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Default breaker settings.
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHalfOpenMax      = 1
+)
+
+// DefaultFailureCodes are the codes counted as failures by default.
+var DefaultFailureCodes = []errx.Code{
+	errx.CodeUnavailable,
+	errx.CodeDeadlineExceeded,
+	errx.CodeInternal,
+	errx.CodeResourceExhausted,
+}
+
+// Option configures a Breaker.
+type Option func(*Breaker)
+
+// WithName sets the breaker name, recorded as the source of rejection errors.
+func WithName(name string) Option {
+	return func(b *Breaker) {
+		b.name = name
+	}
+}
+
+// WithFailureThreshold sets how many consecutive failures open the breaker.
+// Values below 1 are treated as 1.
+func WithFailureThreshold(n int) Option {
+	return func(b *Breaker) {
+		b.threshold = max(n, 1)
+	}
+}
+
+// WithOpenTimeout sets how long the breaker stays open before allowing trial calls.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.openTimeout = d
+	}
+}
+
+// WithHalfOpenMax sets how many trial calls may run concurrently while half-open,
+// and how many must succeed in a row to close the breaker.
+// Values below 1 are treated as 1.
+func WithHalfOpenMax(n int) Option {
+	return func(b *Breaker) {
+		b.halfOpenMax = max(n, 1)
+	}
+}
+
+// WithFailureCodes replaces the set of codes counted as failures. Errors that
+// contain no *errx.Error are still counted, except context.Canceled.
+func WithFailureCodes(codes ...errx.Code) Option {
+	return func(b *Breaker) {
+		b.isFailure = failureCodes(codes)
+	}
+}
+
+// failureCodes returns a predicate counting errors with one of codes, and errors
+// without an errx code, as failures. A caller canceling its call is not a
+// failure of the protected service.
+func failureCodes(codes []errx.Code) func(error) bool {
+	return func(err error) bool {
+		if !errx.Is(err) {
+			return !errors.Is(err, context.Canceled)
+		}
+		return errx.CodeIn(err, codes...)
+	}
+}
+
+// WithFailurePredicate replaces the function deciding whether an error counts
+// as a failure. It is only called with non-nil errors.
+func WithFailurePredicate(fn func(error) bool) Option {
+	return func(b *Breaker) {
+		b.isFailure = fn
+	}
+}
+
+// WithStateChange registers a callback invoked on every state transition.
+// Callbacks run synchronously after the breaker's lock is released.
+func WithStateChange(fn func(from, to State)) Option {
+	return func(b *Breaker) {
+		b.onChange = append(b.onChange, fn)
+	}
+}
+
+// WithClock sets the time source, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(b *Breaker) {
+		b.now = now
+	}
+}
+
+// Breaker is a circuit breaker. The zero value is not usable; create one with New.
+// A Breaker is safe for concurrent use.
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+	halfOpenMax int
+	isFailure   func(error) bool
+	onChange    []func(from, to State)
+	now         func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64    // incremented on every state change
+	failures   int       // consecutive failures while closed
+	successes  int       // consecutive successes while half-open
+	inFlight   int       // trial calls running while half-open
+	openedAt   time.Time // when the breaker last opened
+}
+
+// New creates a closed Breaker.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		threshold:   DefaultFailureThreshold,
+		openTimeout: DefaultOpenTimeout,
+		halfOpenMax: DefaultHalfOpenMax,
+		isFailure:   failureCodes(DefaultFailureCodes),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the current state, moving from open to half-open if the open
+// timeout has elapsed.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from := b.state
+	b.advance()
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return to
+}
+
+// Do runs fn if the breaker allows it and records the outcome.
+// If the breaker rejects the call, fn is not run and the rejection error is returned.
+// Otherwise the error from fn is returned unchanged.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// Allow reports whether a call may proceed. If it may, the returned function must
+// be called exactly once with the call's result. If it may not, Allow returns an
+// unavailable, retryable *errx.Error with a retry delay hint.
+func (b *Breaker) Allow() (func(error), error) {
+	b.mu.Lock()
+	from := b.state
+	b.advance()
+
+	switch b.state {
+	case StateOpen:
+		wait := b.openedAt.Add(b.openTimeout).Sub(b.now())
+		b.mu.Unlock()
+		b.notify(from, StateOpen)
+		return nil, b.rejection(wait)
+	case StateHalfOpen:
+		if b.inFlight >= b.halfOpenMax {
+			b.mu.Unlock()
+			b.notify(from, StateHalfOpen)
+			return nil, b.rejection(0)
+		}
+		b.inFlight++
+	}
+	to, generation := b.state, b.generation
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return func(err error) { b.record(generation, err) }, nil
+}
+
+// record applies the outcome of a call allowed during the given generation.
+// Outcomes of calls that started before the last state change are ignored.
+func (b *Breaker) record(generation uint64, err error) {
+	failed := err != nil && b.isFailure(err)
+
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	from := b.state
+	switch b.state {
+	case StateClosed:
+		if failed {
+			b.failures++
+			if b.failures >= b.threshold {
+				b.open()
+			}
+		} else if err == nil {
+			b.failures = 0
+		}
+	case StateHalfOpen:
+		b.inFlight--
+		if failed {
+			b.open()
+		} else if err == nil {
+			b.successes++
+			if b.successes >= b.halfOpenMax {
+				b.setState(StateClosed)
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// advance moves an open breaker to half-open once the open timeout has elapsed.
+// The caller must hold b.mu.
+func (b *Breaker) advance() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.openTimeout)) {
+		b.setState(StateHalfOpen)
+	}
+}
+
+// open moves the breaker to the open state. The caller must hold b.mu.
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState changes state and resets the counters. The caller must hold b.mu.
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.inFlight = 0
+}
+
+// notify invokes the state change callbacks if the state changed.
+func (b *Breaker) notify(from, to State) {
+	if from == to {
+		return
+	}
+	for _, fn := range b.onChange {
+		fn(from, to)
+	}
+}
+
+// rejection builds the error returned for a call the breaker does not allow.
+func (b *Breaker) rejection(wait time.Duration) *errx.Error {
+	e := errx.NewUnavailable("service temporarily unavailable").
+		WithRetryAfter(wait).
+		WithDebug("circuit breaker is open").
+		WithTags("circuit_breaker")
+	if b.name != "" {
+		e.WithSource(b.name)
+	}
+	return e
+}
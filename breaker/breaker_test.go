@@ -0,0 +1,268 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/breaker"
+)
+
+type breakerSuite struct {
+	suite.Suite
+
+	now         time.Time
+	transitions []string
+}
+
+func TestBreakerSuite(t *testing.T) {
+	suite.Run(t, new(breakerSuite))
+}
+
+func (s *breakerSuite) SetupTest() {
+	s.now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.transitions = nil
+}
+
+func (s *breakerSuite) newBreaker(opts ...breaker.Option) *breaker.Breaker {
+	base := []breaker.Option{
+		breaker.WithClock(func() time.Time { return s.now }),
+		breaker.WithFailureThreshold(3),
+		breaker.WithOpenTimeout(10 * time.Second),
+		breaker.WithStateChange(func(from, to breaker.State) {
+			s.transitions = append(s.transitions, from.String()+"->"+to.String())
+		}),
+	}
+	return breaker.New(append(base, opts...)...)
+}
+
+func (s *breakerSuite) call(b *breaker.Breaker, err error) error {
+	return b.Do(context.Background(), func(context.Context) error { return err })
+}
+
+func (s *breakerSuite) TestStateString() {
+	s.Equal("closed", breaker.StateClosed.String())
+	s.Equal("open", breaker.StateOpen.String())
+	s.Equal("half_open", breaker.StateHalfOpen.String())
+	s.Equal("unknown", breaker.State(99).String())
+}
+
+func (s *breakerSuite) TestOpensAfterConsecutiveFailures() {
+	b := s.newBreaker()
+	down := errx.NewUnavailable("down")
+
+	for range 2 {
+		s.Equal(down, s.call(b, down))
+	}
+	s.Equal(breaker.StateClosed, b.State())
+
+	s.Equal(down, s.call(b, down))
+	s.Equal(breaker.StateOpen, b.State())
+	s.Equal([]string{"closed->open"}, s.transitions)
+}
+
+func (s *breakerSuite) TestSuccessResetsFailures() {
+	b := s.newBreaker()
+	down := errx.NewInternal("boom")
+
+	s.call(b, down)
+	s.call(b, down)
+	s.NoError(s.call(b, nil))
+	s.call(b, down)
+	s.call(b, down)
+
+	s.Equal(breaker.StateClosed, b.State())
+}
+
+func (s *breakerSuite) TestClientErrorsDoNotCount() {
+	b := s.newBreaker()
+
+	for _, err := range []error{
+		errx.NewInvalidArgument("bad"),
+		errx.NewNotFound("missing"),
+		errx.NewPermissionDenied("nope"),
+		context.Canceled,
+		fmt.Errorf("query: %w", context.Canceled),
+	} {
+		for range 5 {
+			s.Equal(err, s.call(b, err))
+		}
+	}
+
+	s.Equal(breaker.StateClosed, b.State())
+	s.Empty(s.transitions)
+}
+
+func (s *breakerSuite) TestDefaultFailureCodes() {
+	for _, code := range breaker.DefaultFailureCodes {
+		s.Run(code.String(), func() {
+			b := s.newBreaker(breaker.WithFailureThreshold(1))
+			s.call(b, errx.New(code, "fail"))
+			s.Equal(breaker.StateOpen, b.State())
+		})
+	}
+}
+
+func (s *breakerSuite) TestNonErrxErrorsCount() {
+	for name, err := range map[string]error{
+		"plain":    errors.New("connection refused"),
+		"url":      &url.Error{Op: "Get", URL: "http://payments", Err: errors.New("dial tcp: connection refused")},
+		"deadline": context.DeadlineExceeded,
+	} {
+		s.Run(name, func() {
+			b := s.newBreaker(breaker.WithFailureThreshold(1))
+			s.call(b, err)
+			s.Equal(breaker.StateOpen, b.State())
+		})
+	}
+}
+
+func (s *breakerSuite) TestFailureCodesCountNonErrxErrors() {
+	b := s.newBreaker(breaker.WithFailureThreshold(1), breaker.WithFailureCodes(errx.CodeDataLoss))
+	s.call(b, errx.NewUnavailable("down"))
+	s.Equal(breaker.StateClosed, b.State())
+
+	s.call(b, errors.New("connection refused"))
+	s.Equal(breaker.StateOpen, b.State())
+}
+
+func (s *breakerSuite) TestRejectsWhenOpen() {
+	b := s.newBreaker(breaker.WithName("payments"))
+	for range 3 {
+		s.call(b, errx.NewUnavailable("down"))
+	}
+
+	s.now = s.now.Add(4 * time.Second)
+
+	ran := false
+	err := b.Do(context.Background(), func(context.Context) error {
+		ran = true
+		return nil
+	})
+
+	s.False(ran)
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+	s.True(errx.IsRetryable(err))
+
+	d, ok := errx.RetryDelay(err)
+	s.True(ok)
+	s.Equal(6*time.Second, d)
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal("payments", e.Source())
+	s.Contains(e.Tags(), "circuit_breaker")
+}
+
+func (s *breakerSuite) TestHalfOpenSuccessCloses() {
+	b := s.newBreaker()
+	for range 3 {
+		s.call(b, errx.NewUnavailable("down"))
+	}
+
+	s.now = s.now.Add(10 * time.Second)
+	s.Equal(breaker.StateHalfOpen, b.State())
+
+	s.NoError(s.call(b, nil))
+	s.Equal(breaker.StateClosed, b.State())
+	s.Equal([]string{"closed->open", "open->half_open", "half_open->closed"}, s.transitions)
+}
+
+func (s *breakerSuite) TestHalfOpenFailureReopens() {
+	b := s.newBreaker()
+	for range 3 {
+		s.call(b, errx.NewUnavailable("down"))
+	}
+
+	s.now = s.now.Add(10 * time.Second)
+	s.call(b, errx.NewDeadlineExceeded("slow"))
+
+	s.Equal(breaker.StateOpen, b.State())
+	s.Equal([]string{"closed->open", "open->half_open", "half_open->open"}, s.transitions)
+
+	d, ok := errx.RetryDelay(s.call(b, nil))
+	s.True(ok)
+	s.Equal(10*time.Second, d, "the open timeout restarts")
+}
+
+func (s *breakerSuite) TestHalfOpenLimitsTrialCalls() {
+	b := s.newBreaker(breaker.WithHalfOpenMax(2))
+	for range 3 {
+		s.call(b, errx.NewUnavailable("down"))
+	}
+	s.now = s.now.Add(10 * time.Second)
+
+	done1, err := b.Allow()
+	s.Require().NoError(err)
+	done2, err := b.Allow()
+	s.Require().NoError(err)
+
+	_, err = b.Allow()
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+
+	done1(nil)
+	s.Equal(breaker.StateHalfOpen, b.State(), "one success is not enough")
+	done2(nil)
+	s.Equal(breaker.StateClosed, b.State())
+}
+
+func (s *breakerSuite) TestStaleOutcomesIgnored() {
+	b := s.newBreaker(breaker.WithFailureThreshold(1))
+
+	done, err := b.Allow()
+	s.Require().NoError(err)
+
+	s.call(b, errx.NewUnavailable("down"))
+	s.Equal(breaker.StateOpen, b.State())
+
+	// A success from a call started before the breaker opened does not close it.
+	done(nil)
+	s.Equal(breaker.StateOpen, b.State())
+}
+
+func (s *breakerSuite) TestCustomFailureCodes() {
+	b := s.newBreaker(breaker.WithFailureThreshold(1), breaker.WithFailureCodes(errx.CodeAborted))
+
+	s.call(b, errx.NewUnavailable("down"))
+	s.Equal(breaker.StateClosed, b.State())
+
+	s.call(b, errx.NewAborted("conflict"))
+	s.Equal(breaker.StateOpen, b.State())
+}
+
+func (s *breakerSuite) TestCustomFailurePredicate() {
+	b := s.newBreaker(breaker.WithFailureThreshold(1), breaker.WithFailurePredicate(func(err error) bool {
+		return !errx.Is(err)
+	}))
+
+	s.call(b, errx.NewInternal("boom"))
+	s.Equal(breaker.StateClosed, b.State())
+
+	s.call(b, errors.New("plain"))
+	s.Equal(breaker.StateOpen, b.State())
+}
+
+func (s *breakerSuite) TestConcurrentUse() {
+	b := breaker.New(breaker.WithFailureThreshold(1000))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			var err error
+			if i%2 == 0 {
+				err = errx.NewUnavailable("down")
+			}
+			_ = b.Do(context.Background(), func(context.Context) error { return err })
+		})
+	}
+	wg.Wait()
+
+	s.Equal(breaker.StateClosed, b.State())
+}
@@ -0,0 +1,339 @@
+// Package catalog describes domain errors in a definition file and generates
+// typed errx constructors and reference documentation from it.
+//
+// A catalog file is YAML (or JSON, which is valid YAML):
+//
+//	package: invites
+//	errors:
+//	  - name: InviteExpired
+//	    code: failed_precondition
+//	    reason: INVITE_EXPIRED
+//	    message: "invite {invite_id} has expired"
+//	    description: The invite link is older than its expiry window.
+//	    docs_url: https://docs.example.com/errors#invite-expired
+//	    retryable: false
+//	    details:
+//	      - name: invite_id
+//	        type: string
+//
+// Placeholders in the message refer to detail fields by name. Each definition
+// becomes a sentinel (ErrInviteExpired) usable with errors.Is and typed
+// constructors (NewInviteExpired, WrapInviteExpired); see [Catalog.GenerateGo].
+//
+// A [Registry] collects definitions from one or more catalogs and exports them as
+// Markdown, as a JSON Schema of the errx wire format, or as OpenAPI components.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+)
+
+// FieldTypes lists the Go types a detail field may have.
+var FieldTypes = []string{"string", "bool", "int", "int64", "float64", "time.Time", "time.Duration"}
+
+// Catalog is a set of error definitions belonging to one Go package.
+type Catalog struct {
+	Package string       `yaml:"package" json:"package"`
+	Errors  []Definition `yaml:"errors" json:"errors"`
+}
+
+// Definition describes a single domain error.
+type Definition struct {
+	Name        string  `yaml:"name" json:"name"`                                   // Go name, e.g. "InviteExpired"
+	Code        string  `yaml:"code" json:"code"`                                   // errx code name, e.g. "failed_precondition"
+	Reason      string  `yaml:"reason,omitempty" json:"reason,omitempty"`           // Defaults to the name in SCREAMING_SNAKE_CASE
+	Message     string  `yaml:"message" json:"message"`                             // Client message, may contain {field} placeholders
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"` // Documentation for maintainers and API consumers
+	DocsURL     string  `yaml:"docs_url,omitempty" json:"docs_url,omitempty"`       // Link to further documentation
+	Retryable   *bool   `yaml:"retryable,omitempty" json:"retryable,omitempty"`     // Explicit retry decision; nil leaves the code default
+	Details     []Field `yaml:"details,omitempty" json:"details,omitempty"`         // Client-safe detail fields
+}
+
+// Field describes a client-safe detail attached to a domain error.
+type Field struct {
+	Name        string `yaml:"name" json:"name"`                                   // Detail key, e.g. "invite_id"
+	Type        string `yaml:"type" json:"type"`                                   // One of FieldTypes
+	Description string `yaml:"description,omitempty" json:"description,omitempty"` // Documentation
+}
+
+// fieldPattern matches valid detail field names.
+var fieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Load reads and validates a catalog file.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read catalog: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML or JSON catalog. Defaults, such as reasons
+// derived from names, are filled in.
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse catalog: %w", err)
+	}
+	for i := range c.Errors {
+		c.Errors[i].setDefaults()
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate reports every problem found in the catalog, joined into one error.
+func (c *Catalog) Validate() error {
+	var errs []error
+	if c.Package != "" && !isIdentifier(c.Package) {
+		errs = append(errs, fmt.Errorf("package %q is not a valid Go identifier", c.Package))
+	}
+
+	names := make(map[string]bool)
+	reasons := make(map[string]bool)
+	for i, d := range c.Errors {
+		where := fmt.Sprintf("errors[%d] (%s)", i, d.Name)
+		if d.Name == "" {
+			where = fmt.Sprintf("errors[%d]", i)
+		}
+		for _, p := range d.problems() {
+			errs = append(errs, fmt.Errorf("%s: %s", where, p))
+		}
+
+		if d.Name != "" && names[GoName(d.Name)] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name", where))
+		}
+		names[GoName(d.Name)] = true
+
+		if d.Reason != "" && reasons[d.Reason] {
+			errs = append(errs, fmt.Errorf("%s: duplicate reason %q", where, d.Reason))
+		}
+		reasons[d.Reason] = true
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports every problem found in the definition, joined into one error.
+func (d Definition) Validate() error {
+	var errs []error
+	for _, p := range d.problems() {
+		errs = append(errs, fmt.Errorf("%s: %s", d.Name, p))
+	}
+	return errors.Join(errs...)
+}
+
+// problems lists the problems found in the definition on its own.
+func (d Definition) problems() []string {
+	var problems []string
+	if d.Name == "" {
+		problems = append(problems, "name is required")
+	} else if !isIdentifier(GoName(d.Name)) {
+		problems = append(problems, "name does not form a Go identifier")
+	} else if id, ok := reservedName(GoName(d.Name)); ok {
+		problems = append(problems, fmt.Sprintf("name %q is reserved: it generates %s", GoName(d.Name), id))
+	}
+
+	if _, ok := errx.ParseCode(d.Code); !ok {
+		problems = append(problems, fmt.Sprintf("unknown code %q", d.Code))
+	}
+	if d.Message == "" {
+		problems = append(problems, "message is required")
+	}
+
+	fields := make(map[string]bool)
+	params := make(map[string]string) // Go parameter name to detail name
+	for _, f := range d.Details {
+		if !fieldPattern.MatchString(f.Name) {
+			problems = append(problems, fmt.Sprintf("detail %q is not a valid identifier", f.Name))
+		}
+		if fields[f.Name] {
+			problems = append(problems, fmt.Sprintf("duplicate detail %q", f.Name))
+		} else if other, ok := params[paramName(f.Name)]; ok {
+			problems = append(problems, fmt.Sprintf("details %q and %q both become parameter %s", other, f.Name, paramName(f.Name)))
+		}
+		fields[f.Name] = true
+		params[paramName(f.Name)] = f.Name
+		if !slices.Contains(FieldTypes, f.Type) {
+			problems = append(problems, fmt.Sprintf("detail %q has unsupported type %q", f.Name, f.Type))
+		}
+	}
+	for _, p := range Placeholders(d.Message) {
+		if !fields[p] {
+			problems = append(problems, fmt.Sprintf("message placeholder {%s} has no matching detail", p))
+		}
+	}
+	return problems
+}
+
+// ErrxCode returns the definition's errx code, or CodeUnknown if it is invalid.
+func (d Definition) ErrxCode() errx.Code {
+	code, _ := errx.ParseCode(d.Code)
+	return code
+}
+
+// setDefaults fills in the reason from the name if it is empty.
+func (d *Definition) setDefaults() {
+	if d.Reason == "" {
+		d.Reason = screamingSnake(d.Name)
+	}
+}
+
+// HTTPStatus returns the HTTP status code used for the definition's errx code.
+func (d Definition) HTTPStatus() int {
+	return errxhttp.StatusCode(d.ErrxCode())
+}
+
+// Placeholders returns the field names referenced by {field} placeholders in
+// message, in order of appearance.
+func Placeholders(message string) []string {
+	return errx.Placeholders(message)
+}
+
+// reservedSchemas are the schema names declared by WriteOpenAPI besides one per
+// definition.
+var reservedSchemas = map[string]bool{
+	"Error": true,
+}
+
+// reservedTypeScript are the identifiers declared by WriteTypeScript besides the
+// <Name>Error, <Name>Details and is<Name> declarations of each definition.
+var reservedTypeScript = map[string]bool{
+	"ErrxCode":       true,
+	"errxCodes":      true,
+	"ErrxError":      true,
+	"KnownError":     true,
+	"ErrxReason":     true,
+	"isErrxError":    true,
+	"isKnownError":   true,
+	"parseErrxError": true,
+}
+
+// reservedName reports whether a definition with the given Go name would
+// generate an identifier that collides with a fixed one, and returns that
+// identifier.
+func reservedName(name string) (string, bool) {
+	if reservedSchemas[name] {
+		return name, true
+	}
+	for _, id := range []string{name + "Error", name + "Details", "is" + name} {
+		if reservedTypeScript[id] {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// commonInitialisms are rendered in upper case in Go names.
+var commonInitialisms = map[string]bool{
+	"API": true, "DB": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TLS": true, "TTL": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true,
+}
+
+// GoName converts a name such as "invite_expired" or "InviteExpired" to an
+// exported Go identifier ("InviteExpired"), upper-casing common initialisms.
+func GoName(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	return b.String()
+}
+
+// paramName converts a detail field name to an unexported Go identifier
+// ("invite_id" becomes "inviteID"), avoiding Go keywords and the names used by
+// generated code.
+func paramName(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return "_"
+	}
+	first := strings.ToLower(words[0])
+	rest := GoName(strings.Join(words[1:], "_"))
+	ident := first + rest
+	if isKeyword(ident) || ident == "err" || ident == "errx" || ident == "fmt" || ident == "time" {
+		ident += "_"
+	}
+	return ident
+}
+
+// screamingSnake converts a name to SCREAMING_SNAKE_CASE ("InviteExpired"
+// becomes "INVITE_EXPIRED").
+func screamingSnake(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// splitWords splits a snake, kebab, space separated or camel case name into words.
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(current[len(current)-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// isIdentifier reports whether s is a valid Go identifier.
+func isIdentifier(s string) bool {
+	if s == "" || isKeyword(s) {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyword reports whether s is a Go keyword.
+func isKeyword(s string) bool {
+	switch s {
+	case "break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type", "var":
+		return true
+	default:
+		return false
+	}
+}
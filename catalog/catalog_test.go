@@ -0,0 +1,150 @@
+package catalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/catalog"
+)
+
+type catalogSuite struct {
+	suite.Suite
+}
+
+func TestCatalogSuite(t *testing.T) {
+	suite.Run(t, new(catalogSuite))
+}
+
+func (s *catalogSuite) TestLoad() {
+	c, err := catalog.Load(filepath.Join("internal", "invites", "errors.yaml"))
+	s.Require().NoError(err)
+
+	s.Equal("invites", c.Package)
+	s.Require().Len(c.Errors, 3)
+
+	d := c.Errors[0]
+	s.Equal("InviteExpired", d.Name)
+	s.Equal(errx.CodeFailedPrecondition, d.ErrxCode())
+	s.Equal("INVITE_EXPIRED", d.Reason, "reason defaults to the name in screaming snake case")
+	s.Require().NotNil(d.Retryable)
+	s.False(*d.Retryable)
+	s.Equal([]catalog.Field{
+		{Name: "invite_id", Type: "string", Description: "ID of the expired invite."},
+		{Name: "expired_at", Type: "time.Time", Description: "When the invite expired."},
+	}, d.Details)
+
+	s.Equal("INVITE_QUOTA", c.Errors[1].Reason, "explicit reasons are kept")
+	s.Nil(c.Errors[2].Retryable)
+}
+
+func (s *catalogSuite) TestLoadMissingFile() {
+	_, err := catalog.Load(filepath.Join(s.T().TempDir(), "missing.yaml"))
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *catalogSuite) TestParseJSON() {
+	c, err := catalog.Parse([]byte(`{"package":"billing","errors":[{"name":"card_declined","code":"failed_precondition","message":"card declined"}]}`))
+	s.Require().NoError(err)
+	s.Equal("billing", c.Package)
+	s.Equal("CARD_DECLINED", c.Errors[0].Reason)
+}
+
+func (s *catalogSuite) TestParseSyntaxError() {
+	_, err := catalog.Parse([]byte("errors: ["))
+	s.ErrorContains(err, "parse catalog")
+}
+
+func (s *catalogSuite) TestValidate() {
+	tests := map[string]struct {
+		yaml string
+		want string
+	}{
+		"bad package": {
+			yaml: "package: my-pkg\nerrors: []",
+			want: `package "my-pkg" is not a valid Go identifier`,
+		},
+		"reserved name": {
+			yaml: "errors: [{name: error, code: internal, message: x}]",
+			want: `name "Error" is reserved`,
+		},
+		"missing name": {
+			yaml: "errors: [{code: internal, message: x}]",
+			want: "name is required",
+		},
+		"duplicate name": {
+			yaml: "errors: [{name: A, code: internal, message: x, reason: R1}, {name: a, code: internal, message: y, reason: R2}]",
+			want: "duplicate name",
+		},
+		"duplicate reason": {
+			yaml: "errors: [{name: A, code: internal, message: x, reason: R}, {name: B, code: internal, message: y, reason: R}]",
+			want: `duplicate reason "R"`,
+		},
+		"unknown code": {
+			yaml: "errors: [{name: A, code: NOT_FOUND, message: x}]",
+			want: `unknown code "NOT_FOUND"`,
+		},
+		"missing message": {
+			yaml: "errors: [{name: A, code: internal}]",
+			want: "message is required",
+		},
+		"bad detail name": {
+			yaml: "errors: [{name: A, code: internal, message: x, details: [{name: user-id, type: string}]}]",
+			want: `detail "user-id" is not a valid identifier`,
+		},
+		"duplicate detail": {
+			yaml: "errors: [{name: A, code: internal, message: x, details: [{name: id, type: string}, {name: id, type: int}]}]",
+			want: `duplicate detail "id"`,
+		},
+		"colliding parameters": {
+			yaml: "errors: [{name: A, code: internal, message: x, details: [{name: invite_id, type: string}, {name: inviteId, type: string}]}]",
+			want: `details "invite_id" and "inviteId" both become parameter inviteID`,
+		},
+		"unsupported type": {
+			yaml: "errors: [{name: A, code: internal, message: x, details: [{name: id, type: uuid}]}]",
+			want: `detail "id" has unsupported type "uuid"`,
+		},
+		"unmatched placeholder": {
+			yaml: "errors: [{name: A, code: internal, message: 'user {user_id} missing'}]",
+			want: "message placeholder {user_id} has no matching detail",
+		},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			_, err := catalog.Parse([]byte(tt.yaml))
+			s.ErrorContains(err, tt.want)
+		})
+	}
+}
+
+func (s *catalogSuite) TestValidateReportsAllProblems() {
+	_, err := catalog.Parse([]byte("errors: [{name: A, code: bogus}]"))
+	s.ErrorContains(err, "unknown code")
+	s.ErrorContains(err, "message is required")
+}
+
+func (s *catalogSuite) TestPlaceholders() {
+	s.Equal([]string{"user_id", "org"}, catalog.Placeholders("user {user_id} not in {org}"))
+	s.Nil(catalog.Placeholders("no placeholders {} or { spaced }"))
+}
+
+func (s *catalogSuite) TestGoName() {
+	tests := map[string]string{
+		"invite_expired": "InviteExpired",
+		"InviteExpired":  "InviteExpired",
+		"invite-expired": "InviteExpired",
+		"user id":        "UserID",
+		"HTTPError":      "HTTPError",
+		"api_key_bad":    "APIKeyBad",
+		"UserIDMissing":  "UserIDMissing",
+	}
+	for in, want := range tests {
+		s.Run(in, func() {
+			s.Equal(want, catalog.GoName(in))
+		})
+	}
+}
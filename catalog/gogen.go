@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// goTemplate renders the Go source for a catalog.
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by errxgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .NeedsTime }}
+	"time"
+{{- end }}
+
+	"github.com/bjaus/errx"
+)
+{{ range .Errors }}
+// Err{{ .Name }} matches {{ .Name }} errors with errors.Is.
+var Err{{ .Name }} = errx.{{ .New }}(errx.{{ .CodeConst }}, {{ .Message }}).WithReason({{ .Reason }})
+
+// New{{ .Name }} creates {{ .Article }} {{ .Code }} error with reason {{ .ReasonText }}.
+{{- range .Doc }}
+//{{ if . }} {{ . }}{{ end }}
+{{- end }}
+func New{{ .Name }}({{ .Params }}) *errx.Error {
+	return errx.{{ .New }}(errx.{{ .CodeConst }}, {{ .Message }}){{ .Builders }}
+}
+
+// Wrap{{ .Name }} wraps err as {{ .Article }} {{ .Code }} error with reason {{ .ReasonText }}.
+// Returns nil if err is nil.
+func Wrap{{ .Name }}(err error{{ if .Params }}, {{ .Params }}{{ end }}) *errx.Error {
+	if err == nil {
+		return nil
+	}
+	return errx.{{ .Wrap }}(err, errx.{{ .CodeConst }}, {{ .Message }}){{ .Builders }}
+}
+{{ end }}`))
+
+type goFile struct {
+	Package   string
+	NeedsTime bool
+	Errors    []goError
+}
+
+type goError struct {
+	Name       string   // Go name
+	Code       string   // errx code name
+	Article    string   // indefinite article for the code name, "a" or "an"
+	CodeConst  string   // errx code constant
+	Reason     string   // quoted reason
+	ReasonText string   // unquoted reason
+	Message    string   // quoted message or template
+	New        string   // errx constructor, New or NewTemplate
+	Wrap       string   // errx wrapper, Wrap or WrapTemplate
+	Params     string   // parameter list
+	Builders   string   // chained builder calls
+	Doc        []string // extra doc comment lines
+}
+
+// GenerateGo writes gofmt-formatted Go source declaring, for each definition,
+// a sentinel Err<Name> for use with errors.Is and the constructors New<Name> and
+// Wrap<Name> taking one typed parameter per detail field. Messages with
+// placeholders become errx templates rendered from the details.
+func (c *Catalog) GenerateGo(w io.Writer) error {
+	if c.Package == "" {
+		return fmt.Errorf("generate go: package is required")
+	}
+
+	file := goFile{Package: c.Package}
+	for _, d := range c.Errors {
+		ge := goError{
+			Name:       GoName(d.Name),
+			Code:       d.Code,
+			Article:    article(d.Code),
+			CodeConst:  "Code" + GoName(d.Code),
+			Reason:     strconv.Quote(d.Reason),
+			ReasonText: d.Reason,
+		}
+
+		var params, builders []string
+		for _, f := range d.Details {
+			p := paramName(f.Name)
+			params = append(params, p+" "+f.Type)
+			builders = append(builders, fmt.Sprintf(".\n\t\tWithDetail(%s, %s)", strconv.Quote(f.Name), p))
+			if strings.HasPrefix(f.Type, "time.") {
+				file.NeedsTime = true
+			}
+		}
+		if d.Retryable != nil {
+			if *d.Retryable {
+				builders = append(builders, ".\n\t\tWithRetryable()")
+			} else {
+				builders = append(builders, ".\n\t\tWithNonRetryable()")
+			}
+		}
+		ge.Params = strings.Join(params, ", ")
+		ge.Builders = fmt.Sprintf(".\n\t\tWithReason(%s)", ge.Reason) + strings.Join(builders, "")
+
+		ge.Message, ge.New, ge.Wrap = strconv.Quote(d.Message), "New", "Wrap"
+		if len(Placeholders(d.Message)) > 0 {
+			ge.New, ge.Wrap = "NewTemplate", "WrapTemplate"
+		}
+
+		if d.Description != "" {
+			ge.Doc = append(ge.Doc, "")
+			ge.Doc = append(ge.Doc, wrapText(d.Description, 76)...)
+		}
+		if d.DocsURL != "" {
+			ge.Doc = append(ge.Doc, "", "See "+d.DocsURL)
+		}
+		file.Errors = append(file.Errors, ge)
+	}
+
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, file); err != nil {
+		return fmt.Errorf("generate go: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generate go: format: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// article returns the indefinite article for a code name: "an" before a vowel,
+// "a" otherwise.
+func article(word string) string {
+	if word != "" && strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// wrapText splits text into lines of at most width runes, breaking on spaces.
+func wrapText(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && line.Len()+1+len(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,127 @@
+package catalog_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx/catalog"
+)
+
+// update rewrites the generated example package instead of comparing against it.
+var update = flag.Bool("update", false, "update generated files in internal/invites")
+
+type gogenSuite struct {
+	suite.Suite
+}
+
+func TestGogenSuite(t *testing.T) {
+	suite.Run(t, new(gogenSuite))
+}
+
+func (s *gogenSuite) load() *catalog.Catalog {
+	c, err := catalog.Load(filepath.Join("internal", "invites", "errors.yaml"))
+	s.Require().NoError(err)
+	return c
+}
+
+// golden compares got with the named file in internal/invites.
+func (s *gogenSuite) golden(name string, got []byte) {
+	path := filepath.Join("internal", "invites", name)
+	if *update {
+		s.Require().NoError(os.WriteFile(path, got, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(string(want), string(got), "run go generate ./catalog/... to refresh %s", name)
+}
+
+func (s *gogenSuite) TestGenerateGo() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.load().GenerateGo(&buf))
+	s.golden("errors_gen.go", buf.Bytes())
+}
+
+func (s *gogenSuite) TestWriteMarkdown() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.load().WriteMarkdown(&buf))
+	s.golden("ERRORS.md", buf.Bytes())
+}
+
+func (s *gogenSuite) TestGenerateGoRequiresPackage() {
+	c := s.load()
+	c.Package = ""
+	s.ErrorContains(c.GenerateGo(&bytes.Buffer{}), "package is required")
+}
+
+func (s *gogenSuite) TestGenerateGoEscaping() {
+	c, err := catalog.Parse([]byte(`
+package: demo
+errors:
+  - name: Discount
+    code: invalid_argument
+    message: "discount of {pct}% exceeds \"max\""
+    details:
+      - name: pct
+        type: float64
+      - name: type
+        type: string
+`))
+	s.Require().NoError(err)
+
+	var buf bytes.Buffer
+	s.Require().NoError(c.GenerateGo(&buf))
+	src := buf.String()
+	s.Contains(src, `errx.NewTemplate(errx.CodeInvalidArgument, "discount of {pct}% exceeds \"max\"")`)
+	s.NotContains(src, `"fmt"`, "templates are rendered by errx")
+	s.Contains(src, `func NewDiscount(pct float64, type_ string) *errx.Error`)
+	s.Contains(src, "// NewDiscount creates an invalid_argument error")
+	s.Contains(src, "// WrapDiscount wraps err as an invalid_argument error")
+	s.NotContains(src, `"time"`)
+}
+
+func (s *gogenSuite) TestWriteTypeScript() {
+	reg := catalog.NewRegistry()
+	s.Require().NoError(reg.AddCatalog(s.load()))
+
+	var buf bytes.Buffer
+	s.Require().NoError(reg.WriteTypeScript(&buf))
+	s.golden("errors.ts", buf.Bytes())
+}
+
+func (s *gogenSuite) TestWriteTypeScriptWithoutDefinitions() {
+	var buf bytes.Buffer
+	s.Require().NoError(catalog.NewRegistry().WriteTypeScript(&buf))
+	ts := buf.String()
+	s.Contains(ts, `  | "not_found"`)
+	s.Contains(ts, "export type KnownError = never;")
+	s.Contains(ts, "export function isKnownError(value: unknown): value is KnownError {\n  return (\n    false\n  );")
+}
+
+func (s *gogenSuite) TestWriteTypeScriptFieldTypes() {
+	reg := catalog.NewRegistry()
+	s.Require().NoError(reg.Register(catalog.Definition{
+		Name:    "SlowDown",
+		Code:    "resource_exhausted",
+		Message: "slow down */",
+		Details: []catalog.Field{
+			{Name: "wait", Type: "time.Duration"},
+			{Name: "strict", Type: "bool"},
+			{Name: "ratio", Type: "float64"},
+		},
+	}))
+
+	var buf bytes.Buffer
+	s.Require().NoError(reg.WriteTypeScript(&buf))
+	ts := buf.String()
+	s.Contains(ts, "  /** Duration in nanoseconds. */\n  wait: number;")
+	s.Contains(ts, "  strict: boolean;")
+	s.Contains(ts, "  ratio: number;")
+	s.Contains(ts, `typeof d["strict"] === "boolean"`)
+	s.Contains(ts, ` * Message: slow down *\/`, "comment terminators are escaped")
+}
@@ -0,0 +1,7 @@
+// Package invites is an example domain package whose errors are generated by
+// errxgen from errors.yaml, with TypeScript client types generated by errx
+// types. It keeps the generators' output compiled and tested.
+package invites
+
+//go:generate go run ../../../cmd/errxgen -catalog errors.yaml -out errors_gen.go -docs ERRORS.md
+//go:generate go run ../../../cmd/errx types -catalog errors.yaml -out errors.ts
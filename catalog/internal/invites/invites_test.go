@@ -0,0 +1,58 @@
+package invites_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/catalog/internal/invites"
+)
+
+type invitesSuite struct {
+	suite.Suite
+}
+
+func TestInvitesSuite(t *testing.T) {
+	suite.Run(t, new(invitesSuite))
+}
+
+func (s *invitesSuite) TestNew() {
+	expiredAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := invites.NewInviteExpired("inv-1", expiredAt)
+
+	s.Equal(errx.CodeFailedPrecondition, err.Code())
+	s.Equal("INVITE_EXPIRED", err.Reason())
+	s.Equal("invite inv-1 has expired", err.Error())
+	s.Equal("invite {invite_id} has expired", err.Template())
+	s.NoError(err.CheckTemplate())
+	s.Equal(map[string]any{"invite_id": "inv-1", "expired_at": expiredAt}, err.Details())
+	s.Equal(errx.RetryableNo, errx.Retryability(err))
+	s.Contains(err.FormatStackTrace(), "invites.NewInviteExpired")
+}
+
+func (s *invitesSuite) TestSentinels() {
+	err := fmt.Errorf("accept: %w", invites.NewInviteExpired("inv-1", time.Now()))
+
+	s.ErrorIs(err, invites.ErrInviteExpired)
+	s.NotErrorIs(err, invites.ErrInviteNotFound)
+	s.NotErrorIs(invites.NewInviteNotFound(), invites.ErrInviteExpired)
+	s.NotErrorIs(errx.NewFailedPrecondition("other"), invites.ErrInviteExpired)
+}
+
+func (s *invitesSuite) TestWrap() {
+	cause := errors.New("quota table locked")
+	err := invites.WrapInviteQuotaExceeded(cause, 10)
+
+	s.ErrorIs(err, cause)
+	s.ErrorIs(err, invites.ErrInviteQuotaExceeded)
+	s.Equal("you can send at most 10 invites per day", err.Error())
+	s.Equal(10, err.Details()["limit"])
+	s.True(err.IsRetryable())
+
+	s.Nil(invites.WrapInviteQuotaExceeded(nil, 10))
+	s.Nil(invites.WrapInviteNotFound(nil))
+}
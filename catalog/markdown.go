@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WriteMarkdown writes a Markdown reference page listing every definition in a
+// summary table followed by a section per error with its details.
+func (c *Catalog) WriteMarkdown(w io.Writer) error {
+	title := "Error Reference"
+	if c.Package != "" {
+		title = fmt.Sprintf("%s Error Reference", c.Package)
+	}
+	return writeMarkdown(w, title, c.Errors)
+}
+
+// WriteMarkdown writes a Markdown reference page for every registered
+// definition, in the same layout as [Catalog.WriteMarkdown].
+func (r *Registry) WriteMarkdown(w io.Writer) error {
+	return writeMarkdown(w, "Error Reference", r.Definitions())
+}
+
+// writeMarkdown writes a reference page titled title for defs.
+func writeMarkdown(w io.Writer, title string, defs []Definition) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	b.WriteString("| Name | Code | HTTP | Reason | Message | Retryable |\n")
+	b.WriteString("|------|------|------|--------|---------|-----------|\n")
+	for _, d := range defs {
+		fmt.Fprintf(&b, "| [%s](#%s) | `%s` | %d | `%s` | %s | %s |\n",
+			GoName(d.Name), anchor(GoName(d.Name)), d.Code, d.HTTPStatus(), d.Reason, cell(d.Message), retryableText(d.Retryable))
+	}
+
+	for _, d := range defs {
+		fmt.Fprintf(&b, "\n## %s\n\n", GoName(d.Name))
+		if d.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", d.Description)
+		}
+		fmt.Fprintf(&b, "- **Code:** `%s`\n", d.Code)
+		fmt.Fprintf(&b, "- **HTTP status:** %d %s\n", d.HTTPStatus(), http.StatusText(d.HTTPStatus()))
+		fmt.Fprintf(&b, "- **Reason:** `%s`\n", d.Reason)
+		fmt.Fprintf(&b, "- **Message:** `%s`\n", d.Message)
+		fmt.Fprintf(&b, "- **Retryable:** %s\n", retryableText(d.Retryable))
+		if d.DocsURL != "" {
+			fmt.Fprintf(&b, "- **Docs:** <%s>\n", d.DocsURL)
+		}
+		if len(d.Details) > 0 {
+			b.WriteString("\n| Detail | Type | Description |\n")
+			b.WriteString("|--------|------|-------------|\n")
+			for _, f := range d.Details {
+				fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n", f.Name, f.Type, cell(f.Description))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// retryableText describes an explicit retry decision for documentation.
+func retryableText(r *bool) string {
+	switch {
+	case r == nil:
+		return "code default"
+	case *r:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// anchor returns the GitHub-style heading anchor for a heading.
+func anchor(heading string) string {
+	return strings.ToLower(strings.ReplaceAll(heading, " ", "-"))
+}
+
+// cell escapes text for use inside a Markdown table cell.
+func cell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of error definitions, keyed by reason, from which API
+// documentation is exported. Definitions usually come from one or more catalog
+// files; see [Registry.AddCatalog]. A Registry is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]Definition // by reason
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{defs: make(map[string]Definition)}
+}
+
+// Register validates and adds definitions, filling in defaults such as the
+// reason. Registering a definition identical to one already present is a no-op;
+// a different definition with the same reason or name is an error, and nothing
+// is added.
+func (r *Registry) Register(defs ...Definition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make(map[string]Definition, len(defs))
+	names := make(map[string]string, len(r.defs)+len(defs)) // Go name to reason
+	for reason, d := range r.defs {
+		names[GoName(d.Name)] = reason
+	}
+	for _, d := range defs {
+		d.setDefaults()
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("register: %w", err)
+		}
+		existing, ok := r.defs[d.Reason]
+		if !ok {
+			existing, ok = pending[d.Reason]
+		}
+		if ok {
+			if !reflect.DeepEqual(existing, d) {
+				return fmt.Errorf("register %s: reason %q is already registered by %s", d.Name, d.Reason, existing.Name)
+			}
+			continue
+		}
+		if reason, ok := names[GoName(d.Name)]; ok {
+			return fmt.Errorf("register %s: name is already registered with reason %q", d.Name, reason)
+		}
+		names[GoName(d.Name)] = d.Reason
+		pending[d.Reason] = d
+	}
+	for reason, d := range pending {
+		r.defs[reason] = d
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *Registry) MustRegister(defs ...Definition) {
+	if err := r.Register(defs...); err != nil {
+		panic(err)
+	}
+}
+
+// AddCatalog registers every definition in c.
+func (r *Registry) AddCatalog(c *Catalog) error {
+	return r.Register(c.Errors...)
+}
+
+// Lookup returns the definition registered for reason.
+func (r *Registry) Lookup(reason string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.defs[reason]
+	return d, ok
+}
+
+// Definitions returns the registered definitions sorted by name.
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defs := make([]Definition, 0, len(r.defs))
+	for _, d := range r.defs {
+		defs = append(defs, d)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(defs, func(a, b Definition) int {
+		return strings.Compare(GoName(a.Name), GoName(b.Name))
+	})
+	return defs
+}
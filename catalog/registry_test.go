@@ -0,0 +1,175 @@
+package catalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx/catalog"
+)
+
+type registrySuite struct {
+	suite.Suite
+	reg *catalog.Registry
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(registrySuite))
+}
+
+func (s *registrySuite) SetupTest() {
+	c, err := catalog.Load(filepath.Join("internal", "invites", "errors.yaml"))
+	s.Require().NoError(err)
+	s.reg = catalog.NewRegistry()
+	s.Require().NoError(s.reg.AddCatalog(c))
+}
+
+func (s *registrySuite) TestLookupAndDefinitions() {
+	d, ok := s.reg.Lookup("INVITE_QUOTA")
+	s.Require().True(ok)
+	s.Equal("invite_quota_exceeded", d.Name)
+	s.Equal(429, d.HTTPStatus())
+
+	_, ok = s.reg.Lookup("MISSING")
+	s.False(ok)
+
+	var names []string
+	for _, d := range s.reg.Definitions() {
+		names = append(names, catalog.GoName(d.Name))
+	}
+	s.Equal([]string{"InviteExpired", "InviteNotFound", "InviteQuotaExceeded"}, names)
+}
+
+func (s *registrySuite) TestRegister() {
+	reg := catalog.NewRegistry()
+	d := catalog.Definition{Name: "OrderLocked", Code: "aborted", Message: "order is locked"}
+	s.Require().NoError(reg.Register(d))
+	s.Require().NoError(reg.Register(d), "identical definitions may be registered again")
+
+	got, ok := reg.Lookup("ORDER_LOCKED")
+	s.Require().True(ok, "the reason defaults to the name")
+	s.Equal("aborted", got.Code)
+
+	conflict := d
+	conflict.Code = "unavailable"
+	s.ErrorContains(reg.Register(conflict), `reason "ORDER_LOCKED" is already registered`)
+
+	renamed := catalog.Definition{Name: "order_locked", Reason: "LOCKED", Code: "aborted", Message: "locked"}
+	s.ErrorContains(reg.Register(renamed), "name is already registered")
+
+	s.ErrorContains(reg.Register(catalog.Definition{Name: "Bad", Code: "nope", Message: "m"}), `unknown code "nope"`)
+	s.ErrorContains(reg.Register(catalog.Definition{Name: "Error", Code: "internal", Message: "m"}), `name "Error" is reserved`)
+	s.ErrorContains(reg.Register(catalog.Definition{Name: "KnownError", Code: "internal", Message: "m"}), "it generates isKnownError")
+	s.ErrorContains(reg.Register(catalog.Definition{Name: "ErrxError", Code: "internal", Message: "m"}), "it generates isErrxError")
+	s.ErrorContains(reg.Register(catalog.Definition{Name: "Known", Code: "internal", Message: "m"}), "it generates KnownError")
+	s.NoError(reg.Register(catalog.Definition{Name: "ErrxCode", Code: "internal", Message: "m"}), "only generated identifiers collide")
+}
+
+func (s *registrySuite) TestRegisterIsAtomic() {
+	reg := catalog.NewRegistry()
+	err := reg.Register(
+		catalog.Definition{Name: "First", Code: "aborted", Message: "first"},
+		catalog.Definition{Name: "Second", Code: "nope", Message: "second"},
+	)
+	s.Require().Error(err)
+	s.Empty(reg.Definitions())
+}
+
+func (s *registrySuite) TestMustRegisterPanics() {
+	s.Panics(func() {
+		catalog.NewRegistry().MustRegister(catalog.Definition{Name: "Bad"})
+	})
+}
+
+func (s *registrySuite) TestWriteMarkdown() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.reg.WriteMarkdown(&buf))
+	md := buf.String()
+	s.Contains(md, "# Error Reference\n")
+	s.Contains(md, "| [InviteQuotaExceeded](#invitequotaexceeded) | `resource_exhausted` | 429 | `INVITE_QUOTA` |")
+	s.Contains(md, "- **HTTP status:** 400 Bad Request\n")
+}
+
+func (s *registrySuite) TestWriteJSONSchema() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.reg.WriteJSONSchema(&buf))
+
+	var schema struct {
+		Schema     string   `json:"$schema"`
+		Required   []string `json:"required"`
+		Properties struct {
+			Code struct {
+				Enum []string `json:"enum"`
+			} `json:"code"`
+		} `json:"properties"`
+		AllOf []struct {
+			If struct {
+				Properties struct {
+					Reason struct {
+						Const string `json:"const"`
+					} `json:"reason"`
+				} `json:"properties"`
+			} `json:"if"`
+		} `json:"allOf"`
+		Defs map[string]struct {
+			Required   []string                     `json:"required"`
+			Properties map[string]map[string]string `json:"properties"`
+		} `json:"$defs"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &schema))
+
+	s.Equal(catalog.JSONSchemaDialect, schema.Schema)
+	s.Equal([]string{"code", "message"}, schema.Required)
+	s.Contains(schema.Properties.Code.Enum, "failed_precondition")
+	s.Require().Len(schema.AllOf, 3)
+	s.Equal("INVITE_EXPIRED", schema.AllOf[0].If.Properties.Reason.Const)
+
+	expired := schema.Defs["InviteExpiredDetails"]
+	s.Equal([]string{"invite_id", "expired_at"}, expired.Required)
+	s.Equal("date-time", expired.Properties["expired_at"]["format"])
+	s.Equal("integer", schema.Defs["InviteQuotaExceededDetails"].Properties["limit"]["type"])
+}
+
+func (s *registrySuite) TestWriteOpenAPI() {
+	var buf bytes.Buffer
+	s.Require().NoError(s.reg.WriteOpenAPI(&buf, "Invites API", "2.0.0"))
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Components struct {
+			Schemas   map[string]json.RawMessage `json:"schemas"`
+			Responses map[string]struct {
+				Description string `json:"description"`
+				HTTPStatus  int    `json:"x-http-status"`
+				Content     map[string]struct {
+					Schema struct {
+						Ref string `json:"$ref"`
+					} `json:"schema"`
+					Example map[string]string `json:"example"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"components"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &doc))
+
+	s.Equal(catalog.OpenAPIVersion, doc.OpenAPI)
+	s.Equal("Invites API", doc.Info.Title)
+	s.Equal("2.0.0", doc.Info.Version)
+	s.Contains(doc.Components.Schemas, "Error")
+	s.Contains(doc.Components.Schemas, "InviteExpired")
+
+	resp, ok := doc.Components.Responses["InviteNotFound"]
+	s.Require().True(ok)
+	s.Equal(404, resp.HTTPStatus)
+	s.Equal("invite not found", resp.Description, "the message is used without a description")
+	content := resp.Content["application/json"]
+	s.Equal("#/components/schemas/InviteNotFound", content.Schema.Ref)
+	s.Equal("INVITE_NOT_FOUND", content.Example["reason"])
+}
@@ -0,0 +1,198 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect of the exported schemas.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// OpenAPIVersion is the OpenAPI version of the exported documents.
+const OpenAPIVersion = "3.1.0"
+
+// WriteJSONSchema writes a JSON Schema describing the errx wire format
+// (errxhttp.Body) as returned for the registered errors. The code must be one of
+// the errx code names; when the reason is a registered one, the code and the
+// details are constrained to the definition's.
+func (r *Registry) WriteJSONSchema(w io.Writer) error {
+	defs := r.Definitions()
+
+	schema := bodySchema()
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = "errx error"
+
+	var cases []any
+	details := make(map[string]any, len(defs))
+	for _, d := range defs {
+		ref := GoName(d.Name) + "Details"
+		details[ref] = detailsSchema(d)
+		cases = append(cases, map[string]any{
+			"if": map[string]any{
+				"required":   []string{"reason"},
+				"properties": map[string]any{"reason": map[string]any{"const": d.Reason}},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{
+					"code":    map[string]any{"const": d.Code},
+					"details": map[string]any{"$ref": "#/$defs/" + ref},
+				},
+			},
+		})
+	}
+	if len(cases) > 0 {
+		schema["allOf"] = cases
+		schema["$defs"] = details
+	}
+	return writeJSON(w, schema)
+}
+
+// WriteOpenAPI writes an OpenAPI document whose components hold a schema and a
+// response for each registered error, named after the definition, and an "Error"
+// schema for the errx wire format. Operations reference the responses as
+// "#/components/responses/<Name>"; the status each is returned with is recorded in
+// the "x-http-status" extension.
+func (r *Registry) WriteOpenAPI(w io.Writer, title, version string) error {
+	base := bodySchema()
+	base["description"] = "An errx error response."
+
+	schemas := map[string]any{"Error": base}
+	responses := make(map[string]any)
+	for _, d := range r.Definitions() {
+		name := GoName(d.Name)
+
+		properties := map[string]any{
+			"code":   map[string]any{"const": d.Code},
+			"reason": map[string]any{"const": d.Reason},
+		}
+		required := []string{"reason"}
+		if len(d.Details) > 0 {
+			properties["details"] = detailsSchema(d)
+			required = append(required, "details")
+		}
+		schema := map[string]any{
+			"allOf": []any{
+				map[string]any{"$ref": "#/components/schemas/Error"},
+				map[string]any{"type": "object", "required": required, "properties": properties},
+			},
+		}
+		if d.Description != "" {
+			schema["description"] = d.Description
+		}
+		if d.DocsURL != "" {
+			schema["externalDocs"] = map[string]any{"url": d.DocsURL}
+		}
+		schemas[name] = schema
+
+		description := d.Description
+		if description == "" {
+			description = d.Message
+		}
+		responses[name] = map[string]any{
+			"description":   description,
+			"x-http-status": d.HTTPStatus(),
+			"content": map[string]any{
+				errxhttp.ContentTypeJSON: map[string]any{
+					"schema":  map[string]any{"$ref": "#/components/schemas/" + name},
+					"example": map[string]any{"code": d.Code, "reason": d.Reason, "message": d.Message},
+				},
+			},
+		}
+	}
+
+	return writeJSON(w, map[string]any{
+		"openapi":           OpenAPIVersion,
+		"jsonSchemaDialect": JSONSchemaDialect,
+		"info":              map[string]any{"title": title, "version": version},
+		"components":        map[string]any{"schemas": schemas, "responses": responses},
+	})
+}
+
+// bodySchema returns the schema of errxhttp.Body.
+func bodySchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"code", "message"},
+		"properties": map[string]any{
+			"code": map[string]any{
+				"type":        "string",
+				"enum":        errx.CodeNames(),
+				"description": "The errx error code.",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Machine-readable reason identifying the specific error.",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Client-safe error message.",
+			},
+			"error_id": map[string]any{
+				"type":        "string",
+				"description": "Unique ID of this error occurrence, for support requests.",
+			},
+			"details": map[string]any{
+				"type":        "object",
+				"description": "Client-safe structured details.",
+			},
+			"retry_delay": map[string]any{
+				"type":        "string",
+				"pattern":     `^[0-9]+(\.[0-9]+)?s$`,
+				"description": `How long to wait before retrying, in seconds with an "s" suffix.`,
+			},
+			"debug_token": map[string]any{
+				"type":        "string",
+				"description": "Opaque encrypted token for support lookup.",
+			},
+		},
+	}
+}
+
+// detailsSchema returns the schema of a definition's details object.
+func detailsSchema(d Definition) map[string]any {
+	properties := make(map[string]any, len(d.Details))
+	required := make([]string, 0, len(d.Details))
+	for _, f := range d.Details {
+		p := fieldSchema(f.Type)
+		if f.Description != "" {
+			p["description"] = f.Description
+		}
+		properties[f.Name] = p
+		required = append(required, f.Name)
+	}
+	return map[string]any{
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+}
+
+// fieldSchema returns the schema of a detail field's JSON encoding.
+func fieldSchema(typ string) map[string]any {
+	switch typ {
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "int", "int64":
+		return map[string]any{"type": "integer"}
+	case "float64":
+		return map[string]any{"type": "number"}
+	case "time.Time":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "time.Duration":
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds."}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// writeJSON writes v as indented JSON. Map keys are sorted, so the output is
+// stable and suitable for diffing.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
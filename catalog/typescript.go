@@ -0,0 +1,224 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/bjaus/errx"
+)
+
+// tsTemplate renders the TypeScript module for a registry.
+var tsTemplate = template.Must(template.New("ts").Funcs(template.FuncMap{
+	"quote": tsQuote,
+}).Parse(`// Code generated by errx types. DO NOT EDIT.
+
+/** An errx error code. */
+export type ErrxCode =
+{{- range .Codes }}
+  | {{ quote . }}
+{{- end }};
+
+/** Every errx error code. */
+export const errxCodes: readonly ErrxCode[] = [
+{{- range .Codes }}
+  {{ quote . }},
+{{- end }}
+];
+
+/** The errx wire format of an error response body. */
+export type ErrxError = {
+  code: ErrxCode;
+  reason?: string;
+  message: string;
+  /** Unique ID of this error occurrence, for support requests. */
+  error_id?: string;
+  details?: Record<string, unknown>;
+  /** How long to wait before retrying, in seconds with an "s" suffix, e.g. "1.5s". */
+  retry_delay?: string;
+  /** Opaque encrypted token for support lookup. */
+  debug_token?: string;
+};
+{{ range .Errors }}
+{{- if .Fields }}
+/** Details of {{ .Name }} errors. */
+export type {{ .Name }}Details = {
+{{- range .Fields }}
+{{- if .Doc }}
+  /** {{ .Doc }} */
+{{- end }}
+  {{ .Name }}: {{ .Type }};
+{{- end }}
+};
+{{ end }}
+/**
+{{- range .Doc }}
+ *{{ if . }} {{ . }}{{ end }}
+{{- end }}
+ */
+export type {{ .Name }}Error = ErrxError & {
+  code: {{ quote .Code }};
+  reason: {{ quote .Reason }};
+{{- if .Fields }}
+  details: {{ .Name }}Details;
+{{- end }}
+};
+{{ end }}
+/** An error with a registered reason, discriminated by reason. */
+export type KnownError =
+{{- range .Errors }}
+  | {{ .Name }}Error
+{{- else }} never
+{{- end }};
+
+/** A registered reason. */
+export type ErrxReason = KnownError["reason"];
+
+/** Reports whether value is an errx error response body. */
+export function isErrxError(value: unknown): value is ErrxError {
+  if (typeof value !== "object" || value === null) {
+    return false;
+  }
+  const v = value as Record<string, unknown>;
+  const code = v["code"];
+  const details = v["details"];
+  return (
+    typeof code === "string" &&
+    (errxCodes as readonly string[]).includes(code) &&
+    typeof v["message"] === "string" &&
+    (v["reason"] === undefined || typeof v["reason"] === "string") &&
+    (details === undefined || (typeof details === "object" && details !== null)) &&
+    (v["error_id"] === undefined || typeof v["error_id"] === "string") &&
+    (v["retry_delay"] === undefined || typeof v["retry_delay"] === "string") &&
+    (v["debug_token"] === undefined || typeof v["debug_token"] === "string")
+  );
+}
+
+/** Reports whether value is an errx error with a registered reason. */
+export function isKnownError(value: unknown): value is KnownError {
+  return (
+{{- range $i, $e := .Errors }}{{ if $i }} ||{{ end }}
+    is{{ .Name }}(value)
+{{- else }}
+    false
+{{- end }}
+  );
+}
+{{ range .Errors }}
+/** Reports whether value is an error with reason {{ .Reason }}. */
+export function is{{ .Name }}(value: unknown): value is {{ .Name }}Error {
+{{- if .Fields }}
+  if (!isErrxError(value) || value.code !== {{ quote .Code }} || value.reason !== {{ quote .Reason }}) {
+    return false;
+  }
+  const d = value.details;
+  return (
+    d !== undefined
+{{- range .Fields }} &&
+    typeof d[{{ quote .Name }}] === {{ quote .TypeOf }}
+{{- end }}
+  );
+{{- else }}
+  return isErrxError(value) && value.code === {{ quote .Code }} && value.reason === {{ quote .Reason }};
+{{- end }}
+}
+{{ end }}
+/** Parses a response body, returning the errx error it holds or undefined. */
+export function parseErrxError(body: string): ErrxError | undefined {
+  try {
+    const value: unknown = JSON.parse(body);
+    return isErrxError(value) ? value : undefined;
+  } catch {
+    return undefined;
+  }
+}
+`))
+
+type tsFile struct {
+	Codes  []string
+	Errors []tsError
+}
+
+type tsError struct {
+	Name   string    // Go name
+	Code   string    // errx code name
+	Reason string    // reason
+	Doc    []string  // doc comment lines
+	Fields []tsField // detail fields
+}
+
+type tsField struct {
+	Name   string // JSON key
+	Type   string // TypeScript type
+	TypeOf string // typeof result for the JSON value
+	Doc    string // doc comment
+}
+
+// WriteTypeScript writes a TypeScript module for parsing errx error responses:
+// the ErrxCode union of every errx code name, the ErrxError wire type, a
+// <Name>Error type per registered definition with its typed details, the
+// KnownError union of those discriminated by reason, and type guards for each.
+func (r *Registry) WriteTypeScript(w io.Writer) error {
+	file := tsFile{Codes: errx.CodeNames()}
+	for _, d := range r.Definitions() {
+		te := tsError{
+			Name:   GoName(d.Name),
+			Code:   d.Code,
+			Reason: d.Reason,
+		}
+		if d.Description != "" {
+			te.Doc = append(te.Doc, wrapText(tsComment(d.Description), 76)...)
+		} else {
+			te.Doc = append(te.Doc, fmt.Sprintf("A %s error with reason %s.", d.Code, d.Reason))
+		}
+		te.Doc = append(te.Doc, "", fmt.Sprintf("Message: %s", tsComment(d.Message)))
+		if d.DocsURL != "" {
+			te.Doc = append(te.Doc, "", fmt.Sprintf("@see %s", tsComment(d.DocsURL)))
+		}
+		for _, f := range d.Details {
+			typ, typeOf := tsType(f.Type)
+			doc := tsComment(f.Description)
+			switch f.Type {
+			case "time.Time":
+				doc = strings.TrimSpace(doc + " RFC 3339 timestamp.")
+			case "time.Duration":
+				doc = strings.TrimSpace(doc + " Duration in nanoseconds.")
+			}
+			te.Fields = append(te.Fields, tsField{Name: f.Name, Type: typ, TypeOf: typeOf, Doc: doc})
+		}
+		file.Errors = append(file.Errors, te)
+	}
+
+	var b strings.Builder
+	if err := tsTemplate.Execute(&b, file); err != nil {
+		return fmt.Errorf("generate typescript: %w", err)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tsType returns the TypeScript type of a detail field's JSON encoding and the
+// matching typeof result.
+func tsType(typ string) (string, string) {
+	switch typ {
+	case "bool":
+		return "boolean", "boolean"
+	case "int", "int64", "float64", "time.Duration":
+		return "number", "number"
+	default:
+		return "string", "string"
+	}
+}
+
+// tsQuote returns s as a TypeScript string literal.
+func tsQuote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// tsComment makes text safe to place inside a /** */ comment.
+func tsComment(text string) string {
+	return strings.ReplaceAll(text, "*/", "*\\/")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bjaus/errx/debugtoken"
+)
+
+// keyEnv is the environment variable holding the debug token key when neither
+// -key nor -key-file is given.
+const keyEnv = "ERRX_DEBUG_KEY"
+
+// stdin is read when decode-token is given no token argument; tests replace it.
+var stdin io.Reader = os.Stdin
+
+// runDecodeToken implements "errx decode-token": it decrypts a debug token
+// returned to a client and prints its payload as JSON.
+func runDecodeToken(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("errx decode-token", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	keyText := fs.String("key", "", "key as hex or base64 (default $"+keyEnv+")")
+	keyFile := fs.String("key-file", "", "path of a file holding the key as hex or base64")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("decode-token takes at most one token")
+	}
+
+	key, err := loadKey(*keyText, *keyFile)
+	if err != nil {
+		return err
+	}
+
+	token := fs.Arg(0)
+	if token == "" || token == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("read token: %w", err)
+		}
+		token = string(data)
+	}
+	if strings.TrimSpace(token) == "" {
+		return fmt.Errorf("no token given")
+	}
+
+	payload, err := debugtoken.Open(key, token)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(payload)
+}
+
+// loadKey returns the key given with -key, -key-file or the environment, in that
+// order of precedence.
+func loadKey(text, file string) ([]byte, error) {
+	switch {
+	case text != "":
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read key: %w", err)
+		}
+		text = string(data)
+	default:
+		text = os.Getenv(keyEnv)
+		if text == "" {
+			return nil, fmt.Errorf("no key given: use -key, -key-file or $%s", keyEnv)
+		}
+	}
+	return debugtoken.ParseKey(text)
+}
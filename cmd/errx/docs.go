@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bjaus/errx/catalog"
+)
+
+// stringsFlag is a repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// runDocs implements "errx docs": it loads one or more catalogs into a registry
+// and writes the requested export.
+func runDocs(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("errx docs", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var catalogs stringsFlag
+	fs.Var(&catalogs, "catalog", "path to a catalog file (YAML or JSON); may be repeated")
+	format := fs.String("format", "markdown", "output format: markdown, jsonschema or openapi")
+	out := fs.String("out", "", "path of the output file (default stdout)")
+	title := fs.String("title", "Errors", "OpenAPI info title")
+	version := fs.String("version", "1.0.0", "OpenAPI info version")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(catalogs) == 0 {
+		fs.Usage()
+		return fmt.Errorf("-catalog is required")
+	}
+
+	reg, err := loadRegistry(catalogs)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "markdown", "md":
+		err = reg.WriteMarkdown(&buf)
+	case "jsonschema":
+		err = reg.WriteJSONSchema(&buf)
+	case "openapi":
+		err = reg.WriteOpenAPI(&buf, *title, *version)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, stdout, buf.Bytes())
+}
+
+// loadRegistry loads the catalog files into a new registry.
+func loadRegistry(paths []string) (*catalog.Registry, error) {
+	reg := catalog.NewRegistry()
+	for _, path := range paths {
+		c, err := catalog.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := reg.AddCatalog(c); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return reg, nil
+}
+
+// writeOutput writes data to the file at path, or to stdout if path is empty.
+func writeOutput(path string, stdout io.Writer, data []byte) error {
+	if path == "" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,71 @@
+// Command errx provides tooling around errx error catalogs.
+//
+// Usage:
+//
+//	errx <command> [flags]
+//
+// Commands:
+//
+//	docs          export catalog definitions as Markdown, JSON Schema or OpenAPI
+//	types         generate TypeScript client types for codes and catalog definitions
+//	decode-token  decrypt a debug token returned to a client
+//
+// Run "errx <command> -h" for the flags of a command. Output is deterministic, so
+// CI can regenerate it and diff against the committed copy.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is an errx subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) error
+}
+
+// commands lists the subcommands in the order they are shown in the usage.
+var commands = []command{
+	{name: "docs", summary: "export catalog definitions as Markdown, JSON Schema or OpenAPI", run: runDocs},
+	{name: "types", summary: "generate TypeScript client types for codes and catalog definitions", run: runTypes},
+	{name: "decode-token", summary: "decrypt a debug token returned to a client", run: runDecodeToken},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "errx:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches to the subcommand named by the first argument.
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return fmt.Errorf("no command given")
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	usage(stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// usage writes the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: errx <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", c.name, c.summary)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/debugtoken"
+)
+
+type mainSuite struct {
+	suite.Suite
+	dir     string
+	catalog string
+}
+
+func TestMainSuite(t *testing.T) {
+	suite.Run(t, new(mainSuite))
+}
+
+func (s *mainSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.catalog = filepath.Join(s.dir, "errors.yaml")
+	catalog := "package: orders\nerrors:\n  - name: OrderLocked\n    code: aborted\n    message: order {order_id} is locked\n    details:\n      - name: order_id\n        type: string\n"
+	s.Require().NoError(os.WriteFile(s.catalog, []byte(catalog), 0o644))
+}
+
+func (s *mainSuite) TestUnknownCommand() {
+	var stderr bytes.Buffer
+	s.ErrorContains(run([]string{"bogus"}, &bytes.Buffer{}, &stderr), `unknown command "bogus"`)
+	s.Contains(stderr.String(), "docs")
+
+	s.ErrorContains(run(nil, &bytes.Buffer{}, &bytes.Buffer{}), "no command given")
+}
+
+func (s *mainSuite) TestHelp() {
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"help"}, &stdout, &bytes.Buffer{}))
+	s.Contains(stdout.String(), "Usage: errx <command>")
+}
+
+func (s *mainSuite) TestDocsMarkdown() {
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"docs", "-catalog", s.catalog}, &stdout, &bytes.Buffer{}))
+	s.Contains(stdout.String(), "## OrderLocked")
+	s.Contains(stdout.String(), "- **HTTP status:** 409 Conflict")
+}
+
+func (s *mainSuite) TestDocsFormatsToFile() {
+	for _, format := range []string{"jsonschema", "openapi"} {
+		out := filepath.Join(s.dir, format+".json")
+		s.Require().NoError(run([]string{"docs", "-catalog", s.catalog, "-format", format, "-out", out}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+		data, err := os.ReadFile(out)
+		s.Require().NoError(err)
+		s.True(json.Valid(data), format)
+		s.Contains(string(data), "ORDER_LOCKED", format)
+	}
+}
+
+func (s *mainSuite) TestDocsMultipleCatalogs() {
+	other := filepath.Join(s.dir, "other.yaml")
+	s.Require().NoError(os.WriteFile(other, []byte("errors:\n  - name: CartEmpty\n    code: failed_precondition\n    message: cart is empty\n"), 0o644))
+
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"docs", "-catalog", s.catalog, "-catalog", other}, &stdout, &bytes.Buffer{}))
+	s.Contains(stdout.String(), "## CartEmpty")
+	s.Contains(stdout.String(), "## OrderLocked")
+}
+
+func (s *mainSuite) TestDocsErrors() {
+	s.ErrorContains(run([]string{"docs"}, &bytes.Buffer{}, &bytes.Buffer{}), "-catalog is required")
+	s.ErrorContains(run([]string{"docs", "-catalog", s.catalog, "-format", "pdf"}, &bytes.Buffer{}, &bytes.Buffer{}), `unknown format "pdf"`)
+
+	// The same reason defined differently in two catalogs is a conflict.
+	dup := filepath.Join(s.dir, "dup.yaml")
+	s.Require().NoError(os.WriteFile(dup, []byte("errors:\n  - name: OrderLocked\n    code: unavailable\n    message: locked\n"), 0o644))
+	s.ErrorContains(run([]string{"docs", "-catalog", s.catalog, "-catalog", dup}, &bytes.Buffer{}, &bytes.Buffer{}), "already registered")
+}
+
+func (s *mainSuite) TestTypes() {
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"types", "-catalog", s.catalog}, &stdout, &bytes.Buffer{}))
+	ts := stdout.String()
+	s.Contains(ts, "export type ErrxCode =")
+	s.Contains(ts, "export type OrderLockedError = ErrxError & {")
+	s.Contains(ts, "export function isOrderLocked(value: unknown): value is OrderLockedError {")
+}
+
+func (s *mainSuite) TestTypesCodesOnly() {
+	out := filepath.Join(s.dir, "errx.ts")
+	s.Require().NoError(run([]string{"types", "-out", out}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	data, err := os.ReadFile(out)
+	s.Require().NoError(err)
+	s.Contains(string(data), "export type KnownError = never;")
+}
+
+func (s *mainSuite) TestTypesJSONSchema() {
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"types", "-catalog", s.catalog, "-format", "jsonschema"}, &stdout, &bytes.Buffer{}))
+	s.True(json.Valid(stdout.Bytes()))
+
+	s.ErrorContains(run([]string{"types", "-format", "elm"}, &bytes.Buffer{}, &bytes.Buffer{}), `unknown format "elm"`)
+}
+
+func (s *mainSuite) TestDecodeToken() {
+	key := bytes.Repeat([]byte{1}, 32)
+	sealer, err := debugtoken.NewSealer(key)
+	s.Require().NoError(err)
+	token, err := sealer.Seal(errx.NewInternal("boom").WithDebug("nil pointer in handler"))
+	s.Require().NoError(err)
+
+	keyFile := filepath.Join(s.dir, "debug.key")
+	s.Require().NoError(os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600))
+
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"decode-token", "-key-file", keyFile, token}, &stdout, &bytes.Buffer{}))
+
+	var p debugtoken.Payload
+	s.Require().NoError(json.Unmarshal(stdout.Bytes(), &p))
+	s.Equal("internal", p.Code)
+	s.Contains(p.Debug, "nil pointer in handler")
+}
+
+func (s *mainSuite) TestDecodeTokenFromStdinAndEnv() {
+	key := bytes.Repeat([]byte{2}, 16)
+	sealer, err := debugtoken.NewSealer(key)
+	s.Require().NoError(err)
+	token, err := sealer.Seal(errx.NewNotFound("user not found"))
+	s.Require().NoError(err)
+
+	s.T().Setenv(keyEnv, base64.StdEncoding.EncodeToString(key))
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader(token + "\n")
+
+	var stdout bytes.Buffer
+	s.Require().NoError(run([]string{"decode-token"}, &stdout, &bytes.Buffer{}))
+	s.Contains(stdout.String(), `"code": "not_found"`)
+}
+
+func (s *mainSuite) TestDecodeTokenErrors() {
+	s.T().Setenv(keyEnv, "")
+	s.ErrorContains(run([]string{"decode-token", "v1.x"}, &bytes.Buffer{}, &bytes.Buffer{}), "no key given")
+
+	key := hex.EncodeToString(bytes.Repeat([]byte{3}, 32))
+	s.ErrorContains(run([]string{"decode-token", "-key", key, "v1.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}, &bytes.Buffer{}, &bytes.Buffer{}),
+		"wrong key or corrupted token")
+}
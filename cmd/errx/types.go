@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// runTypes implements "errx types": it writes client types for the errx codes
+// and the definitions in the given catalogs.
+func runTypes(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("errx types", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var catalogs stringsFlag
+	fs.Var(&catalogs, "catalog", "path to a catalog file (YAML or JSON); may be repeated")
+	format := fs.String("format", "typescript", "output format: typescript or jsonschema")
+	out := fs.String("out", "", "path of the output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	reg, err := loadRegistry(catalogs)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "typescript", "ts":
+		err = reg.WriteTypeScript(&buf)
+	case "jsonschema":
+		err = reg.WriteJSONSchema(&buf)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, stdout, buf.Bytes())
+}
@@ -0,0 +1,82 @@
+// Command errxgen generates typed errx constructors and a Markdown reference
+// page from an error catalog file.
+//
+// Usage:
+//
+//	errxgen -catalog errors.yaml [-out errors_gen.go] [-docs ERRORS.md] [-package name]
+//
+// It is typically run with go generate:
+//
+//	//go:generate go run github.com/bjaus/errx/cmd/errxgen -catalog errors.yaml -out errors_gen.go -docs ERRORS.md
+//
+// See package github.com/bjaus/errx/catalog for the catalog file format.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bjaus/errx/catalog"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "errxgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses args and performs the generation. Go source is written to stdout
+// when no -out file is given.
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("errxgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	catalogPath := fs.String("catalog", "", "path to the catalog file (YAML or JSON)")
+	out := fs.String("out", "", "path of the generated Go file (default stdout)")
+	docs := fs.String("docs", "", "path of the generated Markdown reference (optional)")
+	pkg := fs.String("package", "", "Go package name, overriding the catalog's package")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *catalogPath == "" {
+		fs.Usage()
+		return fmt.Errorf("-catalog is required")
+	}
+
+	c, err := catalog.Load(*catalogPath)
+	if err != nil {
+		return err
+	}
+	if *pkg != "" {
+		c.Package = *pkg
+	}
+	if c.Package == "" {
+		c.Package = os.Getenv("GOPACKAGE")
+	}
+
+	var src bytes.Buffer
+	if err := c.GenerateGo(&src); err != nil {
+		return err
+	}
+	if *out == "" {
+		if _, err := stdout.Write(src.Bytes()); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(*out, src.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	if *docs != "" {
+		var md bytes.Buffer
+		if err := c.WriteMarkdown(&md); err != nil {
+			return err
+		}
+		if err := os.WriteFile(*docs, md.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
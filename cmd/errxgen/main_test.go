@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type mainSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestMainSuite(t *testing.T) {
+	suite.Run(t, new(mainSuite))
+}
+
+func (s *mainSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	catalog := "package: orders\nerrors:\n  - name: OrderLocked\n    code: aborted\n    message: order is locked\n"
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "errors.yaml"), []byte(catalog), 0o644))
+}
+
+func (s *mainSuite) TestWritesFiles() {
+	out := filepath.Join(s.dir, "errors_gen.go")
+	docs := filepath.Join(s.dir, "ERRORS.md")
+
+	err := run([]string{"-catalog", filepath.Join(s.dir, "errors.yaml"), "-out", out, "-docs", docs}, &bytes.Buffer{}, &bytes.Buffer{})
+	s.Require().NoError(err)
+
+	src, err := os.ReadFile(out)
+	s.Require().NoError(err)
+	s.Contains(string(src), "package orders")
+	s.Contains(string(src), "func NewOrderLocked() *errx.Error")
+
+	md, err := os.ReadFile(docs)
+	s.Require().NoError(err)
+	s.Contains(string(md), "## OrderLocked")
+}
+
+func (s *mainSuite) TestStdoutAndPackageOverride() {
+	var stdout bytes.Buffer
+	err := run([]string{"-catalog", filepath.Join(s.dir, "errors.yaml"), "-package", "shop"}, &stdout, &bytes.Buffer{})
+	s.Require().NoError(err)
+	s.Contains(stdout.String(), "package shop")
+}
+
+func (s *mainSuite) TestMissingCatalogFlag() {
+	var stderr bytes.Buffer
+	err := run(nil, &bytes.Buffer{}, &stderr)
+	s.ErrorContains(err, "-catalog is required")
+	s.Contains(stderr.String(), "Usage")
+}
+
+func (s *mainSuite) TestInvalidCatalog() {
+	path := filepath.Join(s.dir, "bad.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte("errors: [{name: X, code: nope, message: m}]"), 0o644))
+
+	err := run([]string{"-catalog", path}, &bytes.Buffer{}, &bytes.Buffer{})
+	s.ErrorContains(err, `unknown code "nope"`)
+}
@@ -25,3 +25,10 @@ package errx
 //			unauthenticated,      // Valid authentication credentials required
 //		)
 type Code uint8
+
+// ParseCode returns the Code whose string form is name (e.g. "not_found").
+// Returns CodeUnknown and false if name is not a defined code.
+func ParseCode(name string) (Code, bool) {
+	code, ok := _CodeValue[name]
+	return code, ok
+}
@@ -124,3 +124,17 @@ func (s *codeSuite) TestCodeValues() {
 		})
 	}
 }
+
+func (s *codeSuite) TestParseCode() {
+	for _, code := range errx.CodeValues() {
+		s.Run(code.String(), func() {
+			parsed, ok := errx.ParseCode(code.String())
+			s.True(ok)
+			s.Equal(code, parsed)
+		})
+	}
+
+	parsed, ok := errx.ParseCode("NOT_FOUND")
+	s.False(ok)
+	s.Equal(errx.CodeUnknown, parsed)
+}
@@ -0,0 +1,326 @@
+// Package debugerrors serves a page listing recent errors, for looking at
+// failures during an incident without searching the logs.
+//
+// A Recorder keeps the most recent errors in a bounded ring buffer and is an
+// http.Handler. Mount it next to net/http/pprof, behind the same access
+// controls, since it shows internal debug data:
+//
+//	mux.Handle("/debug/errors", debugerrors.Default)
+//
+//	debugerrors.Record(err)                 // wherever errors are handled or logged
+//	errx.OnCreate(debugerrors.Default.Hook) // or as errors are created
+//
+// The page lists errors newest first with their debug message, metadata and
+// symbolized stack trace. Query parameters filter the list by code, source and
+// tag ("?code=internal&tag=db"); "?format=json", or an Accept header preferring
+// application/json, selects the JSON view. Errors are copied and redacted with
+// errx.DefaultRedactor when they are recorded, so later changes to an error are
+// not shown.
+package debugerrors
+
+import (
+	"encoding/json"
+	"html/template"
+	"maps"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// DefaultCapacity is the number of errors kept by a Recorder.
+const DefaultCapacity = 256
+
+// Default is the Recorder used by Record.
+var Default = New()
+
+// Record records err with the Default recorder.
+func Record(err error) {
+	Default.Record(err)
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithCapacity sets how many errors are kept. Values below 1 are treated as 1.
+func WithCapacity(n int) Option {
+	return func(r *Recorder) {
+		r.capacity = max(n, 1)
+	}
+}
+
+// WithClock sets the time source for recording times, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(r *Recorder) {
+		r.now = now
+	}
+}
+
+// Frame is a symbolized stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Entry is a recorded error as shown by the handler.
+type Entry struct {
+	Time        time.Time      `json:"time"` // When the error was recorded
+	ID          string         `json:"id,omitempty"`
+	Code        string         `json:"code"`
+	Reason      string         `json:"reason,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Message     string         `json:"message"`
+	Tags        []string       `json:"tags,omitempty"`
+	Fingerprint string         `json:"fingerprint"`
+	Debug       string         `json:"debug"` // errx.Error.DebugMessage, including causes
+	Details     map[string]any `json:"details,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Stack       []Frame        `json:"stack,omitempty"`
+}
+
+// Filter selects entries. Empty fields match every entry.
+type Filter struct {
+	Code   string
+	Source string
+	Tag    string
+}
+
+// match reports whether e passes the filter.
+func (f Filter) match(e Entry) bool {
+	return (f.Code == "" || e.Code == f.Code) &&
+		(f.Source == "" || e.Source == f.Source) &&
+		(f.Tag == "" || slices.Contains(e.Tags, f.Tag))
+}
+
+// record is a snapshot of an error taken when it was recorded. The stack trace
+// is symbolized when viewed.
+type record struct {
+	entry Entry
+	stack []uintptr
+}
+
+// Recorder keeps the most recently recorded errors and serves them over HTTP.
+// It is safe for concurrent use.
+type Recorder struct {
+	capacity int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	records []record // ring buffer
+	next    int      // index of the next write
+}
+
+// New creates a Recorder.
+func New(opts ...Option) *Recorder {
+	r := &Recorder{capacity: DefaultCapacity, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Record adds err, evicting the oldest error if the buffer is full. Nil errors
+// are ignored.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+	r.add(newRecord(err, r.now()))
+}
+
+// Hook records errors as they are created. Register it with errx.OnCreate:
+//
+//	errx.OnCreate(debugerrors.Default.Hook)
+//
+// Creation hooks run before methods such as WithSource or WithMeta, so the
+// entry shows the code, message, cause and stack trace, but not the fields set
+// afterwards. Call Record where errors are handled to see everything.
+func (r *Recorder) Hook(e *errx.Error) {
+	r.Record(e)
+}
+
+// add appends rec to the ring buffer.
+func (r *Recorder) add(rec record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.records) < r.capacity {
+		r.records = append(r.records, rec)
+	} else {
+		r.records[r.next] = rec
+	}
+	r.next = (r.next + 1) % r.capacity
+}
+
+// Reset removes every recorded error.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+	r.next = 0
+}
+
+// Entries returns the recorded errors matching f, newest first.
+func (r *Recorder) Entries(f Filter) []Entry {
+	r.mu.Lock()
+	records := make([]record, 0, len(r.records))
+	for i := range len(r.records) {
+		// Walk backwards from the latest write.
+		records = append(records, r.records[(r.next-1-i+2*len(r.records))%len(r.records)])
+	}
+	r.mu.Unlock()
+
+	entries := make([]Entry, 0, len(records))
+	for _, rec := range records {
+		if e := rec.entry; f.match(e) {
+			e.Stack = frames(rec.stack)
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// newRecord copies err, recorded at t, with the current redaction policy. The
+// copy does not share maps with err, which its creator may still modify.
+func newRecord(err error, t time.Time) record {
+	red := errx.DefaultRedactor()
+	e, ok := errx.As(err)
+	if !ok {
+		msg := red.Message(err)
+		return record{entry: Entry{
+			Time:        t,
+			Code:        errx.CodeUnknown.String(),
+			Message:     msg,
+			Fingerprint: errx.Fingerprint(err),
+			Debug:       msg,
+		}}
+	}
+	return record{
+		entry: Entry{
+			Time:        t,
+			ID:          e.ID(),
+			Code:        e.Code().String(),
+			Reason:      e.Reason(),
+			Source:      e.Source(),
+			Message:     red.Message(e),
+			Tags:        slices.Clone(e.Tags()),
+			Fingerprint: errx.Fingerprint(e),
+			Debug:       e.DebugMessage(),
+			Details:     red.Map(maps.Clone(e.Details())),
+			Metadata:    red.Map(maps.Clone(e.Metadata())),
+		},
+		stack: e.StackTrace(),
+	}
+}
+
+// frames symbolizes a stack trace.
+func frames(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var out []Frame
+	it := runtime.CallersFrames(pcs)
+	for {
+		f, more := it.Next()
+		out = append(out, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			return out
+		}
+	}
+}
+
+// page is the data of the HTML view.
+type page struct {
+	Filter   Filter
+	Entries  []Entry
+	Capacity int
+}
+
+// ServeHTTP implements http.Handler. The query parameters "code", "source" and
+// "tag" filter the entries, "limit" bounds their number, and "format=json"
+// selects the JSON view.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	f := Filter{Code: q.Get("code"), Source: q.Get("source"), Tag: q.Get("tag")}
+	entries := r.Entries(f)
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit >= 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if wantsJSON(req) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(struct {
+			Entries []Entry `json:"entries"`
+		}{entries})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = pageTemplate.Execute(w, page{Filter: f, Entries: entries, Capacity: r.capacity})
+}
+
+// wantsJSON reports whether the request asks for the JSON view.
+func wantsJSON(req *http.Request) bool {
+	if format := req.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	accept := req.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Recent errors</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+form input { margin-right: 1em; }
+details { border-top: 1px solid #ccc; padding: .5em 0; }
+summary { cursor: pointer; }
+code, pre { font-size: 90%; }
+pre { background: #f6f6f6; padding: .5em; overflow-x: auto; }
+.code { font-weight: bold; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>Recent errors</h1>
+<form method="get">
+<label>Code <input name="code" value="{{ .Filter.Code }}"></label>
+<label>Source <input name="source" value="{{ .Filter.Source }}"></label>
+<label>Tag <input name="tag" value="{{ .Filter.Tag }}"></label>
+<button type="submit">Filter</button>
+<a href="?format=json">JSON</a>
+</form>
+<p class="meta">{{ len .Entries }} errors shown, newest first; up to {{ .Capacity }} are kept.</p>
+{{ range .Entries }}
+<details>
+<summary><span class="code">{{ .Code }}</span>{{ with .Reason }} {{ . }}{{ end }} {{ .Message }}
+<span class="meta">{{ time .Time }}{{ with .Source }} · {{ . }}{{ end }}{{ with .ID }} · {{ . }}{{ end }}</span></summary>
+<p class="meta">fingerprint <code>{{ .Fingerprint }}</code>{{ with .Tags }} · tags {{ range . }}<code>{{ . }}</code> {{ end }}{{ end }}</p>
+<pre>{{ .Debug }}</pre>
+{{ with .Details }}<p>Details</p><pre>{{ range $k, $v := . }}{{ $k }}: {{ $v }}
+{{ end }}</pre>{{ end }}
+{{ with .Metadata }}<p>Metadata</p><pre>{{ range $k, $v := . }}{{ $k }}: {{ $v }}
+{{ end }}</pre>{{ end }}
+{{ with .Stack }}<p>Stack</p><pre>{{ range . }}{{ .Function }}
+	{{ .File }}:{{ .Line }}
+{{ end }}</pre>{{ end }}
+</details>
+{{ else }}
+<p>{{ if or .Filter.Code .Filter.Source .Filter.Tag }}No errors match the filter.{{ else }}No errors recorded.{{ end }}</p>
+{{ end }}
+</body>
+</html>
+`))
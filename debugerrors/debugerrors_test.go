@@ -0,0 +1,174 @@
+package debugerrors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/debugerrors"
+)
+
+type debugErrorsSuite struct {
+	suite.Suite
+	now time.Time
+	rec *debugerrors.Recorder
+}
+
+func TestDebugErrorsSuite(t *testing.T) {
+	suite.Run(t, new(debugErrorsSuite))
+}
+
+func (s *debugErrorsSuite) SetupTest() {
+	s.now = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	s.rec = debugerrors.New(
+		debugerrors.WithCapacity(3),
+		debugerrors.WithClock(func() time.Time {
+			s.now = s.now.Add(time.Second)
+			return s.now
+		}),
+	)
+}
+
+func (s *debugErrorsSuite) get(target string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.rec.ServeHTTP(w, req)
+	return w
+}
+
+func (s *debugErrorsSuite) getJSON(target string) []debugerrors.Entry {
+	w := s.get(target)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("application/json", w.Header().Get("Content-Type"))
+	var body struct {
+		Entries []debugerrors.Entry `json:"entries"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Entries
+}
+
+func (s *debugErrorsSuite) TestRingBufferNewestFirst() {
+	for i := range 5 {
+		s.rec.Record(errx.NewInternal(fmt.Sprint("failure ", i)))
+	}
+	s.rec.Record(nil)
+
+	var messages []string
+	for _, e := range s.rec.Entries(debugerrors.Filter{}) {
+		messages = append(messages, e.Message)
+	}
+	s.Equal([]string{"failure 4", "failure 3", "failure 2"}, messages)
+
+	s.rec.Reset()
+	s.Empty(s.rec.Entries(debugerrors.Filter{}))
+}
+
+func (s *debugErrorsSuite) TestJSONView() {
+	s.rec.Record(errx.WrapInternal(errors.New("connection refused"), "database unavailable").
+		WithSource("orders").
+		WithTags("db").
+		WithMeta("api_key", "k-123").
+		WithDebug("dial 10.0.0.7"))
+
+	entries := s.getJSON("/debug/errors?format=json")
+	s.Require().Len(entries, 1)
+	e := entries[0]
+	s.Equal("internal", e.Code)
+	s.Equal("orders", e.Source)
+	s.Equal([]string{"db"}, e.Tags)
+	s.Len(e.Fingerprint, 16)
+	s.Contains(e.Debug, "dial 10.0.0.7")
+	s.Contains(e.Debug, "cause=connection refused")
+	s.Equal(errx.RedactedText, e.Metadata["api_key"])
+	s.Require().NotEmpty(e.Stack)
+	s.Contains(e.Stack[0].Function, "TestJSONView")
+	s.Positive(e.Stack[0].Line)
+}
+
+func (s *debugErrorsSuite) TestFilters() {
+	s.rec.Record(errx.NewInternal("db down").WithSource("orders").WithTags("db"))
+	s.rec.Record(errx.NewNotFound("no order").WithSource("orders"))
+	s.rec.Record(errx.NewInternal("cache down").WithSource("cache"))
+
+	s.Len(s.getJSON("/?format=json&code=internal"), 2)
+	s.Len(s.getJSON("/?format=json&source=orders"), 2)
+	s.Len(s.getJSON("/?format=json&tag=db"), 1)
+	s.Len(s.getJSON("/?format=json&code=internal&source=cache"), 1)
+	s.Len(s.getJSON("/?format=json&limit=1"), 1)
+}
+
+func (s *debugErrorsSuite) TestPlainErrors() {
+	s.rec.Record(errors.New("boom"))
+	entries := s.rec.Entries(debugerrors.Filter{Code: "unknown"})
+	s.Require().Len(entries, 1)
+	s.Equal("boom", entries[0].Message)
+}
+
+func (s *debugErrorsSuite) TestHTMLView() {
+	s.rec.Record(errx.NewInvalidArgument("bad <input>").WithMeta("password", "hunter2"))
+
+	w := s.get("/debug/errors")
+	s.Equal("text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal("no-store", w.Header().Get("Cache-Control"))
+	body := w.Body.String()
+	s.Contains(body, "invalid_argument")
+	s.Contains(body, "bad &lt;input&gt;")
+	s.NotContains(body, "hunter2")
+	s.Contains(body, "TestHTMLView")
+
+	s.Contains(s.get("/debug/errors?code=internal").Body.String(), "No errors match the filter.")
+}
+
+func (s *debugErrorsSuite) TestAcceptJSON() {
+	s.rec.Record(errx.NewInternal("boom"))
+	w := s.get("/", "Accept", "application/json")
+	s.Equal("application/json", w.Header().Get("Content-Type"))
+	s.True(strings.HasPrefix(w.Body.String(), "{"))
+}
+
+func (s *debugErrorsSuite) TestHook() {
+	defer errx.OnCreate(s.rec.Hook)()
+
+	err := errx.NewNotFound("user not found").WithMeta("user_id", "u-1")
+	_ = errx.Wrap(err, errx.CodeInternal, "lookup failed")
+
+	entries := s.rec.Entries(debugerrors.Filter{})
+	s.Require().Len(entries, 2)
+	s.Equal("lookup failed", entries[0].Message)
+	s.Contains(entries[0].Debug, "cause=user not found")
+	s.Equal("user not found", entries[1].Message)
+	s.Empty(entries[1].Metadata, "fields set after creation are not shown")
+	s.NotEmpty(entries[1].Stack)
+}
+
+func (s *debugErrorsSuite) TestSnapshotOnRecord() {
+	err := errx.NewInternal("boom").WithMeta("attempt", 1)
+	s.rec.Record(err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			err.WithMeta("attempt", i)
+		}
+	}()
+	for range 10 {
+		s.get("/debug/errors")
+	}
+	<-done
+
+	entries := s.rec.Entries(debugerrors.Filter{})
+	s.Require().Len(entries, 1)
+	s.Equal(1, entries[0].Metadata["attempt"])
+}
@@ -0,0 +1,166 @@
+// Package debugtoken seals the internal details of an error into an opaque
+// token that can be returned to clients and decrypted later by support
+// engineers.
+//
+// Tokens are encrypted and authenticated with AES-GCM under a key shared by
+// the service and its support tooling. Clients cannot read or forge them:
+//
+//	sealer, err := debugtoken.NewSealer(key) // 16, 24 or 32 bytes
+//
+//	errxhttp.WriteError(w, r, err, errxhttp.WithDebugToken(sealer))
+//	// {"code":"internal","message":"internal error","debug_token":"v1.Zm9v..."}
+//
+// A support engineer decodes a token reported by a customer with the same key,
+// either with [Open] or with the errx command:
+//
+//	errx decode-token -key-file debug.key v1.Zm9v...
+package debugtoken
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// version prefixes tokens produced by this package.
+const version = "v1."
+
+// additionalData binds tokens to this format, so ciphertexts produced with the
+// same key for another purpose are rejected.
+var additionalData = []byte("errx-debug-token-v1")
+
+// Payload is the content of a debug token.
+type Payload struct {
+	Code     string         `json:"code"`
+	Reason   string         `json:"reason,omitempty"`
+	Message  string         `json:"message"`
+	Debug    string         `json:"debug,omitempty"` // errx.Error.DebugMessage, including causes
+	Source   string         `json:"source,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Time     time.Time      `json:"time"` // When the token was sealed
+}
+
+// Option configures a Sealer.
+type Option func(*Sealer)
+
+// WithClock sets the time source for token timestamps, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *Sealer) {
+		s.now = now
+	}
+}
+
+// Sealer creates and opens debug tokens with one key. It is safe for concurrent use.
+type Sealer struct {
+	aead cipher.AEAD
+	now  func() time.Time
+}
+
+// NewSealer creates a Sealer for an AES-128, AES-192 or AES-256 key.
+func NewSealer(key []byte, opts ...Option) (*Sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("debug token key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("debug token key: %w", err)
+	}
+	s := &Sealer{aead: aead, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Seal returns a token holding the code, reason, message, debug message, source
+// and metadata of e and the current time. The message and metadata are
+// redacted with errx.DefaultRedactor, as the debug message already is.
+func (s *Sealer) Seal(e *errx.Error) (string, error) {
+	if e == nil {
+		return "", fmt.Errorf("seal debug token: nil error")
+	}
+	r := errx.DefaultRedactor()
+	return s.SealPayload(Payload{
+		Code:     e.Code().String(),
+		Reason:   e.Reason(),
+		Message:  r.Message(e),
+		Debug:    e.DebugMessage(),
+		Source:   e.Source(),
+		Metadata: r.Map(e.Metadata()),
+		Time:     s.now().UTC(),
+	})
+}
+
+// SealPayload returns a token holding p.
+func (s *Sealer) SealPayload(p Payload) (string, error) {
+	plaintext, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("seal debug token: %w", err)
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("seal debug token: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, plaintext, additionalData)
+	return version + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts and verifies a token created with the same key.
+func (s *Sealer) Open(token string) (*Payload, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(token), version)
+	if !ok {
+		return nil, fmt.Errorf("open debug token: unsupported format")
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("open debug token: %w", err)
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("open debug token: token too short")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("open debug token: wrong key or corrupted token")
+	}
+	var p Payload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, fmt.Errorf("open debug token: %w", err)
+	}
+	return &p, nil
+}
+
+// Open decrypts and verifies a token with key.
+func Open(key []byte, token string) (*Payload, error) {
+	s, err := NewSealer(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.Open(token)
+}
+
+// ParseKey decodes a key written as hex or as standard or URL-safe base64, with
+// or without padding, as stored in configuration and key files.
+func ParseKey(text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if key, err := hex.DecodeString(text); err == nil {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if key, err := enc.DecodeString(text); err == nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("debug token key: not hex or base64")
+}
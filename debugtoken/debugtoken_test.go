@@ -0,0 +1,122 @@
+package debugtoken_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/debugtoken"
+)
+
+type debugTokenSuite struct {
+	suite.Suite
+	key []byte
+	now time.Time
+}
+
+func TestDebugTokenSuite(t *testing.T) {
+	suite.Run(t, new(debugTokenSuite))
+}
+
+func (s *debugTokenSuite) SetupTest() {
+	s.key = bytes.Repeat([]byte{7}, 32)
+	s.now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+}
+
+func (s *debugTokenSuite) sealer() *debugtoken.Sealer {
+	sealer, err := debugtoken.NewSealer(s.key, debugtoken.WithClock(func() time.Time { return s.now }))
+	s.Require().NoError(err)
+	return sealer
+}
+
+func (s *debugTokenSuite) TestRoundTrip() {
+	err := errx.Wrap(errors.New("connection refused"), errx.CodeUnavailable, "billing unavailable").
+		WithReason("BILLING_DOWN").
+		WithSource("billing").
+		WithDebug("dial 10.0.0.7:5432").
+		WithMeta("attempt", 3).
+		WithMeta("api_key", "sk-live-1")
+
+	token, sealErr := s.sealer().Seal(err)
+	s.Require().NoError(sealErr)
+	s.True(strings.HasPrefix(token, "v1."))
+	s.NotContains(token, "billing")
+
+	p, openErr := debugtoken.Open(s.key, token)
+	s.Require().NoError(openErr)
+	s.Equal("unavailable", p.Code)
+	s.Equal("BILLING_DOWN", p.Reason)
+	s.Equal("billing unavailable", p.Message)
+	s.Equal("billing", p.Source)
+	s.Contains(p.Debug, "dial 10.0.0.7:5432")
+	s.Contains(p.Debug, "connection refused")
+	s.EqualValues(3, p.Metadata["attempt"])
+	s.Equal(errx.RedactedText, p.Metadata["api_key"])
+	s.True(p.Time.Equal(s.now))
+	s.Equal(time.UTC, p.Time.Location())
+}
+
+func (s *debugTokenSuite) TestTokensDiffer() {
+	sealer := s.sealer()
+	a, err := sealer.Seal(errx.NewInternal("boom"))
+	s.Require().NoError(err)
+	b, err := sealer.Seal(errx.NewInternal("boom"))
+	s.Require().NoError(err)
+	s.NotEqual(a, b)
+}
+
+func (s *debugTokenSuite) TestOpenRejects() {
+	token, err := s.sealer().Seal(errx.NewInternal("boom"))
+	s.Require().NoError(err)
+
+	_, err = debugtoken.Open(bytes.Repeat([]byte{8}, 32), token)
+	s.ErrorContains(err, "wrong key or corrupted token")
+
+	tampered := token[:len(token)-2] + "AA"
+	if tampered == token {
+		tampered = token[:len(token)-2] + "BB"
+	}
+	_, err = debugtoken.Open(s.key, tampered)
+	s.ErrorContains(err, "wrong key or corrupted token")
+
+	_, err = debugtoken.Open(s.key, "v2."+strings.TrimPrefix(token, "v1."))
+	s.ErrorContains(err, "unsupported format")
+
+	_, err = debugtoken.Open(s.key, "v1.AAAA")
+	s.ErrorContains(err, "token too short")
+
+	_, err = debugtoken.Open(s.key, "v1.!!")
+	s.Error(err)
+}
+
+func (s *debugTokenSuite) TestSealNil() {
+	_, err := s.sealer().Seal(nil)
+	s.Error(err)
+}
+
+func (s *debugTokenSuite) TestNewSealerInvalidKey() {
+	_, err := debugtoken.NewSealer([]byte("short"))
+	s.ErrorContains(err, "debug token key")
+}
+
+func (s *debugTokenSuite) TestParseKey() {
+	for _, text := range []string{
+		hex.EncodeToString(s.key),
+		base64.StdEncoding.EncodeToString(s.key),
+		base64.RawURLEncoding.EncodeToString(s.key) + "\n",
+	} {
+		key, err := debugtoken.ParseKey(text)
+		s.Require().NoError(err, text)
+		s.Equal(s.key, key, text)
+	}
+
+	_, err := debugtoken.ParseKey("not a key!")
+	s.Error(err)
+}
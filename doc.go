@@ -103,6 +103,137 @@
 // WithMetaFromContext uses last-write-wins: if the same key was set via WithMeta, the
 // context value takes precedence. Reverse the call order to give WithMeta priority.
 //
+// # Reasons
+//
+// WithReason refines a code with a machine-readable, client-safe reason. When an
+// errors.Is target has a reason, only errors with the same code and reason match,
+// which makes reason-carrying errors usable as sentinels:
+//
+//	var ErrInviteExpired = errx.NewFailedPrecondition("invite has expired").WithReason("INVITE_EXPIRED")
+//
+//	err := errx.NewFailedPrecondition("invite abc has expired").WithReason("INVITE_EXPIRED")
+//	errors.Is(err, ErrInviteExpired)                        // true
+//	errors.Is(err, errx.NewFailedPrecondition("any"))       // true - target has no reason
+//
+// The errxgen command generates such sentinels and typed constructors from a
+// catalog file; see package github.com/bjaus/errx/catalog.
+//
+// # Operation Trails
+//
+// Op appends an operation name to the nearest *Error in an error's tree, so
+// layers can leave breadcrumbs without wrapping again:
+//
+//	return nil, errx.Op(err, "svc.GetUser")
+//
+// Ops returns the trail deepest first; DebugMessage and LogValue render it.
+//
+// # Message Templates
+//
+// NewTemplate and WrapTemplate take a message whose {name} placeholders are
+// replaced with the matching details when the message is rendered. The template
+// stays constant, which keeps errors groupable and translatable, while the
+// details carry the variable data:
+//
+//	err := errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").
+//	    WithDetail("user_id", id)
+//	err.Error()    // "user 42 not found"
+//	err.Template() // "user {user_id} not found"
+//
+// Placeholders without a detail are rendered as is. Use CheckTemplate or
+// ValidateTemplate in tests to catch them.
+//
+// # Localized Messages
+//
+// WithMessageKey identifies the client message in message catalogs. The message
+// passed to New remains the fallback, and details are available as parameters
+// alongside the params given to WithMessageKey:
+//
+//	errx.SetLocalizer(bundle) // e.g. an *i18n.Bundle
+//
+//	err := errx.NewFailedPrecondition("invite has expired").
+//	    WithDetail("invite_id", id).
+//	    WithMessageKey("invite.expired", nil)
+//
+//	err.LocalizedMessage("de") // translated, or err.Error() if there is no translation
+//
+// Any type implementing Localizer can be used; package github.com/bjaus/errx/i18n
+// provides one backed by JSON catalogs with plural rules, and errxhttp.WriteError
+// negotiates the language from the Accept-Language header.
+//
+// # Redaction
+//
+// LogValue, DebugMessage and the errxhttp renderers pass details, metadata and
+// debug text through the Redactor set with SetRedactor. The default,
+// MaskedRedactor, masks values whose keys contain the words of DefaultDeniedKeys
+// (password, token, ssn, authorization, ...) and scrubs emails, bearer tokens,
+// SSNs and card numbers from strings. Client responses are masked by key but not
+// scrubbed, unless the Redactor is created with WithClientScrubbing. Wrap
+// individual values with Mask to always hide them:
+//
+//	err := errx.NewUnauthenticated("login failed").
+//	    WithMeta("email", errx.Mask(email)). // [REDACTED] in logs and debug output
+//	    WithMeta("password_hint", hint)      // [REDACTED] by key
+//
+// Choose the policy per environment; FullRedactor shows everything:
+//
+//	errx.SetRedactor(errx.RedactorFor(os.Getenv("APP_ENV"))) // full in "dev", masked otherwise
+//
+// # Instance IDs
+//
+// Every error records when it was created (Time). With instance IDs enabled, each
+// error also gets a unique, time-sortable ID that is logged, included in
+// DebugMessage and returned to clients by errxhttp as "error_id", so a reported
+// error can be found in the logs:
+//
+//	errx.SetIDGenerator(errx.NewID) // ULIDs such as 01ARYZ6S41TSV4RRFFQ69G5FAV
+//
+// Tests can make IDs and times deterministic with SetIDGenerator and SetClock.
+//
+// # Fingerprints
+//
+// Fingerprint hashes the code, reason, source, message template and top stack
+// frames of the error where a failure originated, so occurrences of the same
+// failure share a fingerprint even when their messages contain IDs. LogValue
+// includes it as "fingerprint" for log backends to group on:
+//
+//	errx.Fingerprint(err)                            // "3f9c2a6d1e0b7c54"
+//	errx.Fingerprint(err, errx.WithoutLineNumbers()) // stable across code moves
+//
+// # Severity
+//
+// Each code has a Severity, so expected client errors such as not_found log at
+// Info or Warn while internal failures and data loss log at Error. Level maps an
+// error to its slog level and Log logs it there:
+//
+//	errx.Log(ctx, logger, "request failed", err) // INFO for not_found, ERROR for internal
+//
+// WithSeverity overrides the code's severity for one error, and SetSeverities
+// changes the table for all of them.
+//
+// # Faults
+//
+// Code.Fault says who is responsible for an error: FaultClient for codes such as
+// invalid_argument and not_found, FaultServer for codes such as internal and
+// unavailable. FaultOf resolves it over an error's chain for SLOs, and LogValue
+// logs it as "fault":
+//
+//	if errx.FaultOf(err) == errx.FaultServer { ... }
+//
+// # Hooks
+//
+// OnCreate and OnWrap register functions called whenever an *Error is created
+// or wraps a cause, for counting or sampling errors. OnCheck registers
+// functions called with finished errors where they are handled, by Log,
+// errxhttp.WriteError or Check, for policies in tests. All three return a
+// function that unregisters the hook:
+//
+//	errx.OnCreate(agg.Hook)                 // feed an aggregate.Aggregator
+//	t.Cleanup(errx.OnCheck(requireSource)) // check every error handled in one test
+//
+// Creation hooks run before methods such as WithSource, so they see the code,
+// message, cause and stack trace but not fields set afterwards. Check hooks see
+// the error as it was handled.
+//
 // # Ensure Functions
 //
 // Use Ensure and Ensuref to guarantee an error is an *Error without clobbering
@@ -120,6 +251,14 @@
 //	return errx.EnsureInternal(err, "unexpected error")
 //	return errx.EnsurefInternal(err, "unexpected error in %s", "user-service")
 //
+// # Annotating Errors
+//
+// Annotate adds metadata, details or tags to an error from a lower layer without
+// wrapping it. It returns a copy of the nearest *Error, leaving the original
+// unchanged, and wraps other errors with code unknown as Ensure would:
+//
+//	return errx.Annotate(err, errx.WithMeta("order_id", id), errx.WithTags("orders"))
+//
 // # Convenience Functions
 //
 // For each error code, the package provides convenience constructors:
@@ -265,13 +404,31 @@
 //	wrappedErr := fmt.Errorf("payment failed: %w", err)
 //	errx.IsRetryable(wrappedErr)  // true
 //
-// Common retryable scenarios:
+// Use WithRetryAfter to also tell the client when to retry (modeled on google.rpc.RetryInfo).
+// RetryDelay finds the hint anywhere in the error chain:
+//
+//	err := errx.NewResourceExhausted("rate limit exceeded").WithRetryAfter(30 * time.Second)
+//
+//	if d, ok := errx.RetryDelay(err); ok {
+//	    // wait d before retrying
+//	}
+//
+// Retryability is resolved over the whole error chain. The nearest explicit
+// decision wins, so wrapping a retryable error with WrapInternal keeps it
+// retryable, and WithNonRetryable marks an error as definitely not retryable:
+//
+//	err := errx.WrapInternal(unavailableErr, "sync failed").WithNonRetryable()
+//	errx.Retryability(err)  // errx.RetryableNo
+//
+// Without an explicit decision, the nearest code with a default decides.
+// These codes default to retryable:
 //   - CodeUnavailable: Service temporarily down
 //   - CodeDeadlineExceeded: Request timeout
 //   - CodeResourceExhausted: Rate limit exceeded
 //   - CodeAborted: Optimistic locking conflict
 //
-// Non-retryable errors typically include:
+// CodeUnknown and CodeInternal have no default. All other codes default to
+// non-retryable, for example:
 //   - CodeInvalidArgument: Bad request data
 //   - CodeNotFound: Resource doesn't exist
 //   - CodePermissionDenied: Authorization failure
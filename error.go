@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // Compile-time interface assertions
@@ -19,16 +20,26 @@ var (
 // Error represents a rich error with code, context, and debugging information.
 // It implements the standard error interface and supports error wrapping.
 type Error struct {
-	code         Code
-	message      string         // Client-safe message
-	debugMessage string         // Internal debug message
-	cause        error          // Wrapped error
-	source       string         // Source (service/package/component) where error occurred
-	tags         []string       // Tags for categorization
-	details      map[string]any // Client-safe key-value details
-	metadata     map[string]any // Internal debug metadata
-	stackTrace   []uintptr      // Stack trace
-	retryable    bool           // Whether the error indicates a retryable operation
+	code          Code
+	reason        string         // Machine-readable reason refining the code (e.g. "INVITE_EXPIRED")
+	message       string         // Client-safe message, or a template if templated
+	templated     bool           // Whether message is a template rendered from details
+	messageKey    string         // Key of the client message in message catalogs
+	messageParams map[string]any // Parameters for the localized message
+	debugMessage  string         // Internal debug message
+	cause         error          // Wrapped error
+	source        string         // Source (service/package/component) where error occurred
+	tags          []string       // Tags for categorization
+	details       map[string]any // Client-safe key-value details
+	metadata      map[string]any // Internal debug metadata
+	stackTrace    []uintptr      // Stack trace
+	retryable     Retryable      // Explicit retry decision, if any
+	retryAfter    time.Duration  // How long the client should wait before retrying
+	hasRetry      bool           // Whether retryAfter was set
+	severity      Severity       // Explicit severity, if any
+	ops           []string       // Operations the error passed through, in order
+	id            string         // Unique instance ID, if enabled
+	time          time.Time      // When the error was created
 }
 
 // Code returns the error code.
@@ -41,11 +52,15 @@ func (e *Error) Code() Code {
 }
 
 // Error implements the error interface.
-// It returns the client-safe message.
+// It returns the client-safe message, with template placeholders replaced by
+// the matching details for errors created with NewTemplate or WrapTemplate.
 func (e *Error) Error() string {
 	if e == nil {
 		return ""
 	}
+	if e.templated {
+		return Interpolate(e.message, e.details)
+	}
 	return e.message
 }
 
@@ -59,15 +74,32 @@ func (e *Error) Unwrap() error {
 
 // DebugMessage returns a detailed debug message with all context.
 // This should only be logged or shown to system maintainers, never to clients.
+// Sensitive data is redacted by the Redactor set with SetRedactor.
 func (e *Error) DebugMessage() string {
 	if e == nil {
 		return ""
 	}
 
+	r := DefaultRedactor()
 	var parts []string
 
 	// Add code and message
-	parts = append(parts, fmt.Sprintf("[%s] %s", e.code.String(), e.message))
+	parts = append(parts, fmt.Sprintf("[%s] %s", e.code.String(), r.Message(e)))
+
+	// Add instance ID and creation time if present
+	if e.id != "" {
+		parts = append(parts, fmt.Sprintf("id=%s", e.id), fmt.Sprintf("time=%s", e.time.Format(time.RFC3339Nano)))
+	}
+
+	// Add reason if present
+	if e.reason != "" {
+		parts = append(parts, fmt.Sprintf("reason=%s", e.reason))
+	}
+
+	// Add message key if present
+	if e.messageKey != "" {
+		parts = append(parts, fmt.Sprintf("message_key=%s", e.messageKey))
+	}
 
 	// Add source if present
 	if e.source != "" {
@@ -79,29 +111,47 @@ func (e *Error) DebugMessage() string {
 		parts = append(parts, fmt.Sprintf("tags=%v", e.tags))
 	}
 
+	// Add the operation trail of the error and its causes if present
+	if ops := Ops(e); len(ops) > 0 {
+		parts = append(parts, fmt.Sprintf("ops=%s", strings.Join(ops, " > ")))
+	}
+
 	// Add details if present
 	if len(e.details) > 0 {
-		parts = append(parts, fmt.Sprintf("details=%v", e.details))
+		parts = append(parts, fmt.Sprintf("details=%v", r.Map(e.details)))
 	}
 
 	// Add metadata if present
 	if len(e.metadata) > 0 {
-		parts = append(parts, fmt.Sprintf("metadata=%v", e.metadata))
+		parts = append(parts, fmt.Sprintf("metadata=%v", r.Map(e.metadata)))
 	}
 
-	// Add retryable status if true
-	if e.retryable {
+	// Add explicit retry decision if present
+	switch e.retryable {
+	case RetryableYes:
 		parts = append(parts, "retryable=true")
+	case RetryableNo:
+		parts = append(parts, "retryable=false")
+	}
+
+	// Add retry delay hint if present
+	if e.hasRetry {
+		parts = append(parts, fmt.Sprintf("retry_after=%s", e.retryAfter))
+	}
+
+	// Add explicit severity if present
+	if e.severity != SeverityUnspecified {
+		parts = append(parts, fmt.Sprintf("severity=%s", e.severity))
 	}
 
 	// Add debug message if different from message
 	if e.debugMessage != "" && e.debugMessage != e.message {
-		parts = append(parts, fmt.Sprintf("debug=%s", e.debugMessage))
+		parts = append(parts, fmt.Sprintf("debug=%s", r.Scrub(e.debugMessage)))
 	}
 
 	// Add wrapped error
 	if e.cause != nil {
-		parts = append(parts, fmt.Sprintf("cause=%v", e.cause))
+		parts = append(parts, fmt.Sprintf("cause=%s", r.Message(e.cause)))
 	}
 
 	return strings.Join(parts, " | ")
@@ -161,6 +211,18 @@ func (e *Error) WithDebugf(format string, args ...any) *Error {
 	return e.WithDebug(fmt.Sprintf(format, args...))
 }
 
+// WithReason sets a machine-readable reason that refines the error code, such as
+// "INVITE_EXPIRED" for a failed_precondition error. Like the code, the reason is
+// safe to expose to clients. Errors with a reason only match errors.Is targets
+// with the same code and reason.
+func (e *Error) WithReason(reason string) *Error {
+	if e == nil {
+		return nil
+	}
+	e.reason = reason
+	return e
+}
+
 // WithSource sets the source (service/package/component) where the error occurred.
 func (e *Error) WithSource(source string) *Error {
 	if e == nil {
@@ -185,10 +247,43 @@ func (e *Error) WithRetryable() *Error {
 	if e == nil {
 		return nil
 	}
-	e.retryable = true
+	e.retryable = RetryableYes
+	return e
+}
+
+// WithNonRetryable marks the error as definitely not retryable, overriding any
+// retryable error it wraps and the default for its code.
+func (e *Error) WithNonRetryable() *Error {
+	if e == nil {
+		return nil
+	}
+	e.retryable = RetryableNo
+	e.retryAfter = 0
+	e.hasRetry = false
+	return e
+}
+
+// WithRetryAfter marks the error as retryable and records how long the client
+// should wait before retrying, modeled on google.rpc.RetryInfo.
+// Negative durations are treated as zero (retry immediately).
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	if e == nil {
+		return nil
+	}
+	e.retryable = RetryableYes
+	e.retryAfter = max(d, 0)
+	e.hasRetry = true
 	return e
 }
 
+// Reason returns the machine-readable reason set with WithReason.
+func (e *Error) Reason() string {
+	if e == nil {
+		return ""
+	}
+	return e.reason
+}
+
 // Source returns the source (service/package/component) where the error occurred.
 func (e *Error) Source() string {
 	if e == nil {
@@ -229,12 +324,22 @@ func (e *Error) StackTrace() []uintptr {
 	return e.stackTrace
 }
 
-// IsRetryable returns whether the error indicates a retryable operation.
+// IsRetryable returns whether the error indicates a retryable operation,
+// resolved over the error and its causes as described by [Retryability].
 func (e *Error) IsRetryable() bool {
 	if e == nil {
 		return false
 	}
-	return e.retryable
+	return Retryability(e) == RetryableYes
+}
+
+// RetryAfter returns the retry delay hint set with WithRetryAfter.
+// The boolean is false if no hint was set.
+func (e *Error) RetryAfter() (time.Duration, bool) {
+	if e == nil {
+		return 0, false
+	}
+	return e.retryAfter, e.hasRetry
 }
 
 // FormatStackTrace returns a human-readable stack trace.
@@ -258,7 +363,10 @@ func (e *Error) FormatStackTrace() string {
 }
 
 // Is supports error comparison with errors.Is.
-// Two errors are considered equal if they have the same code.
+// Two errors are considered equal if they have the same code and, when the
+// target has a reason, the same reason. This lets a target without a reason
+// match every error with its code, while a sentinel with a reason matches only
+// that specific error.
 func (e *Error) Is(target error) bool {
 	if e == nil {
 		return target == nil
@@ -269,19 +377,47 @@ func (e *Error) Is(target error) bool {
 		return false
 	}
 
-	return e.code == t.code
+	return e.code == t.code && (t.reason == "" || e.reason == t.reason)
 }
 
 // LogValue implements slog.LogValuer for structured logging integration.
-// Returns a slog.GroupValue containing all error fields for debugging.
+// Returns a slog.GroupValue containing all error fields for debugging, with
+// sensitive data redacted by the Redactor set with SetRedactor, the error's
+// [FaultOf] as "fault", and its [Fingerprint] for grouping identical failures.
 func (e *Error) LogValue() slog.Value {
 	if e == nil {
 		return slog.Value{}
 	}
+	return slog.GroupValue(append(e.logAttrs(),
+		slog.String("fault", FaultOf(e).String()),
+		slog.String("fingerprint", Fingerprint(e)),
+	)...)
+}
 
+// logAttrs returns the attributes logged for e, with causes nested under
+// "cause". The fault and fingerprint describe the whole chain, so they are
+// added only by LogValue.
+func (e *Error) logAttrs() []slog.Attr {
+	r := DefaultRedactor()
 	attrs := []slog.Attr{
 		slog.String("code", e.code.String()),
-		slog.String("message", e.message),
+		slog.String("message", r.Message(e)),
+	}
+
+	if e.templated {
+		attrs = append(attrs, slog.String("template", e.message))
+	}
+
+	if e.id != "" {
+		attrs = append(attrs, slog.String("id", e.id), slog.Time("time", e.time))
+	}
+
+	if e.reason != "" {
+		attrs = append(attrs, slog.String("reason", e.reason))
+	}
+
+	if e.messageKey != "" {
+		attrs = append(attrs, slog.String("message_key", e.messageKey))
 	}
 
 	if e.source != "" {
@@ -292,25 +428,43 @@ func (e *Error) LogValue() slog.Value {
 		attrs = append(attrs, slog.Any("tags", e.tags))
 	}
 
+	if len(e.ops) > 0 {
+		attrs = append(attrs, slog.Any("ops", e.ops))
+	}
+
 	if len(e.details) > 0 {
-		attrs = append(attrs, slog.Any("details", e.details))
+		attrs = append(attrs, slog.Any("details", r.Map(e.details)))
 	}
 
 	if len(e.metadata) > 0 {
-		attrs = append(attrs, slog.Any("metadata", e.metadata))
+		attrs = append(attrs, slog.Any("metadata", r.Map(e.metadata)))
+	}
+
+	if e.retryable != RetryableUnspecified {
+		attrs = append(attrs, slog.Bool("retryable", e.retryable == RetryableYes))
 	}
 
-	if e.retryable {
-		attrs = append(attrs, slog.Bool("retryable", true))
+	if e.hasRetry {
+		attrs = append(attrs, slog.Duration("retry_after", e.retryAfter))
+	}
+
+	if e.severity != SeverityUnspecified {
+		attrs = append(attrs, slog.String("severity", e.severity.String()))
 	}
 
 	if e.debugMessage != "" && e.debugMessage != e.message {
-		attrs = append(attrs, slog.String("debug", e.debugMessage))
+		attrs = append(attrs, slog.String("debug", r.Scrub(e.debugMessage)))
 	}
 
 	if e.cause != nil {
-		attrs = append(attrs, slog.Any("cause", e.cause))
+		if cause, ok := e.cause.(*Error); ok {
+			attrs = append(attrs, slog.Attr{Key: "cause", Value: slog.GroupValue(cause.logAttrs()...)})
+		} else if _, ok := e.cause.(slog.LogValuer); ok {
+			attrs = append(attrs, slog.Any("cause", e.cause))
+		} else {
+			attrs = append(attrs, slog.String("cause", r.Message(e.cause)))
+		}
 	}
 
-	return slog.GroupValue(attrs...)
+	return attrs
 }
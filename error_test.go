@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -54,6 +55,31 @@ func (s *errorSuite) TestErrorsIs() {
 	s.False(errors.Is(err1, err3), "errors with different codes should not match")
 }
 
+func (s *errorSuite) TestWithReason() {
+	err := errx.NewFailedPrecondition("invite has expired").WithReason("INVITE_EXPIRED")
+
+	s.Equal("INVITE_EXPIRED", err.Reason())
+	s.Contains(err.DebugMessage(), "reason=INVITE_EXPIRED")
+	s.Contains(err.LogValue().String(), "reason=INVITE_EXPIRED")
+	s.Empty(errx.NewInternal("boom").Reason())
+	s.NotContains(errx.NewInternal("boom").DebugMessage(), "reason=")
+
+	var nilErr *errx.Error
+	s.Nil(nilErr.WithReason("X"))
+	s.Empty(nilErr.Reason())
+}
+
+func (s *errorSuite) TestErrorsIsWithReason() {
+	sentinel := errx.NewFailedPrecondition("invite has expired").WithReason("INVITE_EXPIRED")
+	err := fmt.Errorf("accept invite: %w", errx.NewFailedPrecondition("invite abc has expired").WithReason("INVITE_EXPIRED"))
+
+	s.True(errors.Is(err, sentinel), "same code and reason should match")
+	s.False(errors.Is(errx.NewFailedPrecondition("other"), sentinel), "missing reason should not match")
+	s.False(errors.Is(errx.NewFailedPrecondition("x").WithReason("INVITE_REVOKED"), sentinel), "different reason should not match")
+	s.False(errors.Is(errx.NewNotFound("x").WithReason("INVITE_EXPIRED"), sentinel), "different code should not match")
+	s.True(errors.Is(err, errx.NewFailedPrecondition("any")), "a target without a reason matches by code")
+}
+
 func (s *errorSuite) TestErrorsAs() {
 	baseErr := errors.New("base error")
 	wrappedErr := errx.Wrap(baseErr, errx.CodeInternal, "wrapped")
@@ -102,6 +128,7 @@ func (s *errorSuite) TestNilError() {
 	s.Nil(err.WithSource("source"))
 	s.Nil(err.WithTags("tag"))
 	s.Nil(err.WithRetryable())
+	s.Nil(err.WithNonRetryable())
 
 	// IsRetryable should return false for nil error
 	s.False(err.IsRetryable())
@@ -228,6 +255,40 @@ func (s *errorSuite) TestRetryableInDebugMessage() {
 	s.NotContains(nonRetryableErr.DebugMessage(), "retryable")
 }
 
+func (s *errorSuite) TestWithRetryAfter() {
+	err := errx.New(errx.CodeResourceExhausted, "rate limited")
+	_, ok := err.RetryAfter()
+	s.False(ok)
+
+	err = err.WithRetryAfter(1500 * time.Millisecond)
+	d, ok := err.RetryAfter()
+	s.True(ok)
+	s.Equal(1500*time.Millisecond, d)
+	s.True(err.IsRetryable(), "a retry delay implies the error is retryable")
+
+	// Negative delays clamp to zero
+	d, ok = errx.New(errx.CodeUnavailable, "down").WithRetryAfter(-time.Second).RetryAfter()
+	s.True(ok)
+	s.Zero(d)
+
+	var nilErr *errx.Error
+	s.Nil(nilErr.WithRetryAfter(time.Second))
+	_, ok = nilErr.RetryAfter()
+	s.False(ok)
+}
+
+func (s *errorSuite) TestRetryAfterInDebugMessageAndLogValue() {
+	err := errx.New(errx.CodeResourceExhausted, "rate limited").WithRetryAfter(30 * time.Second)
+	s.Contains(err.DebugMessage(), "retry_after=30s")
+
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("limited", "error", err)
+	s.Contains(buf.String(), `"retry_after":30000000000`)
+
+	s.NotContains(errx.New(errx.CodeInternal, "boom").DebugMessage(), "retry_after")
+}
+
 func (s *errorSuite) TestSlogIntegration() {
 	cause := errors.New("database error")
 	err := errx.Wrap(cause, errx.CodePermissionDenied, "access denied").
@@ -3,8 +3,10 @@ package errx
 import (
 	"errors"
 	"fmt"
+	"iter"
 	"runtime"
 	"slices"
+	"time"
 )
 
 // stackSkipDepth is the number of stack frames to skip when capturing the stack trace.
@@ -125,18 +127,65 @@ func CodeIn(err error, codes ...Code) bool {
 }
 
 // IsRetryable checks if an error indicates a retryable operation.
-// Returns false if the error is not an *Error.
+// The whole error tree is considered, as described by [Retryability].
+// Returns false if the error contains no *Error.
 func IsRetryable(err error) bool {
-	e, ok := As(err)
-	if !ok {
-		return false
+	return Retryability(err) == RetryableYes
+}
+
+// RetryDelay returns the retry delay hint of the first *Error in err's tree that
+// has one, so a hint set deep in the chain survives wrapping.
+// The boolean is false if no error in the tree carries a hint, or if an error
+// marked with WithNonRetryable is found first.
+func RetryDelay(err error) (time.Duration, bool) {
+	for e := range chain(err) {
+		if e.retryable == RetryableNo {
+			return 0, false
+		}
+		if d, ok := e.RetryAfter(); ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// chain yields every *Error in err's tree in depth-first order, following both
+// Unwrap() error and Unwrap() []error.
+func chain(err error) iter.Seq[*Error] {
+	return func(yield func(*Error) bool) {
+		walk(err, yield)
+	}
+}
+
+// walk visits err and its descendants, stopping when yield returns false.
+// It reports whether the walk should continue.
+func walk(err error, yield func(*Error) bool) bool {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			if !yield(e) {
+				return false
+			}
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, child := range u.Unwrap() {
+				if !walk(child, yield) {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
+		}
 	}
-	return e.IsRetryable()
+	return true
 }
 
 // newError is an internal helper that creates an Error with the given parameters.
 func newError(code Code, message string, cause error) *Error {
-	return &Error{
+	e := &Error{
 		code:       code,
 		message:    message,
 		cause:      cause,
@@ -144,6 +193,9 @@ func newError(code Code, message string, cause error) *Error {
 		metadata:   make(map[string]any),
 		stackTrace: captureStackTrace(stackSkipDepth),
 	}
+	e.stamp()
+	runHooks(e)
+	return e
 }
 
 // captureStackTrace captures the current stack trace.
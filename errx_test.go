@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -355,3 +356,37 @@ func (s *errxSuite) TestIsRetryable() {
 	deeplyWrapped := fmt.Errorf("level3: %w", fmt.Errorf("level2: %w", retryableErr))
 	s.True(errx.IsRetryable(deeplyWrapped))
 }
+
+func (s *errxSuite) TestRetryDelay() {
+	_, ok := errx.RetryDelay(nil)
+	s.False(ok)
+
+	_, ok = errx.RetryDelay(errors.New("plain"))
+	s.False(ok)
+
+	_, ok = errx.RetryDelay(errx.NewUnavailable("down").WithRetryable())
+	s.False(ok)
+
+	limited := errx.NewResourceExhausted("rate limited").WithRetryAfter(2 * time.Second)
+	d, ok := errx.RetryDelay(limited)
+	s.True(ok)
+	s.Equal(2*time.Second, d)
+
+	// The hint survives wrapping by errx and non-errx errors
+	wrapped := fmt.Errorf("call failed: %w", errx.WrapInternal(limited, "upstream failed"))
+	d, ok = errx.RetryDelay(wrapped)
+	s.True(ok)
+	s.Equal(2*time.Second, d)
+
+	// The nearest hint wins
+	outer := errx.WrapUnavailable(limited, "busy").WithRetryAfter(5 * time.Second)
+	d, ok = errx.RetryDelay(outer)
+	s.True(ok)
+	s.Equal(5*time.Second, d)
+
+	// Joined errors are searched too
+	joined := errors.Join(errors.New("other"), limited)
+	d, ok = errx.RetryDelay(joined)
+	s.True(ok)
+	s.Equal(2*time.Second, d)
+}
@@ -0,0 +1,159 @@
+package errxhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// maxBodySize limits how much of an error response body is read for decoding.
+const maxBodySize = 1 << 20
+
+// Option configures CheckResponse.
+type Option func(*config)
+
+type config struct {
+	service string
+	now     func() time.Time
+}
+
+// WithService sets the remote service name recorded as the error's Source.
+// Defaults to the host of the request URL.
+func WithService(name string) Option {
+	return func(c *config) {
+		c.service = name
+	}
+}
+
+// WithClock sets the time source used to resolve HTTP-date Retry-After values.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// CheckResponse returns nil if resp has a status below 400, so informational,
+// successful and redirect responses (including 304 Not Modified) pass through.
+// Otherwise it returns an *errx.Error describing the failure:
+//
+//   - The code comes from an errx wire body or problem document when present,
+//     and from the status code otherwise (see [CodeFromStatus]).
+//   - The message and details come from the body, falling back to the status text.
+//   - 429, 502 and 503 responses are marked retryable. A retry delay from the body,
+//     or else from the Retry-After header, is set with errx.Error.WithRetryAfter.
+//   - The source is the remote service name (see [WithService]).
+//
+// The response body is read and replaced so it can still be consumed by the caller.
+func CheckResponse(resp *http.Response, opts ...Option) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	cfg := config{now: time.Now}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.service == "" && resp.Request != nil && resp.Request.URL != nil {
+		cfg.service = resp.Request.URL.Host
+	}
+
+	var data []byte
+	if resp.Body != nil {
+		data, _ = io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	e, ok := decodeBody(resp.Header.Get("Content-Type"), data, resp.StatusCode)
+	if !ok {
+		e = errx.New(CodeFromStatus(resp.StatusCode), http.StatusText(resp.StatusCode))
+		if text := strings.TrimSpace(string(data)); text != "" {
+			e.WithDebug(text)
+		}
+	}
+
+	e.WithSource(cfg.service).WithMeta("status", resp.StatusCode)
+	if resp.Request != nil {
+		e.WithMeta("method", resp.Request.Method)
+		if resp.Request.URL != nil {
+			e.WithMeta("url", resp.Request.URL.Redacted())
+		}
+	}
+
+	if retryableStatus(resp.StatusCode) {
+		e.WithRetryable()
+	}
+	if _, ok := e.RetryAfter(); !ok {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), cfg.now()); ok {
+			e.WithRetryAfter(d)
+		}
+	}
+
+	return e
+}
+
+// Transport is an http.RoundTripper that converts 4xx and 5xx responses into
+// *errx.Error values using [CheckResponse]. Other responses are returned
+// unchanged, so http.Client still follows redirects.
+//
+// When a response is converted, its body is closed and RoundTrip returns a nil
+// response with the error, so http.Client callers see the failure as err
+// (wrapped in a *url.Error, which errx.As and errx.CodeIs see through).
+type Transport struct {
+	// Base is the underlying RoundTripper. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Service is the remote service name recorded as the error's Source.
+	// Defaults to the request host.
+	Service string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	if t.Service != "" {
+		opts = append(opts, WithService(t.Service))
+	}
+	if err := CheckResponse(resp, opts...); err != nil {
+		if resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// parseRetryAfter parses a Retry-After header given either as delay seconds or
+// as an HTTP date relative to now. Dates in the past yield a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
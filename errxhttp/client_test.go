@@ -0,0 +1,325 @@
+package errxhttp_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+)
+
+type clientSuite struct {
+	suite.Suite
+}
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(clientSuite))
+}
+
+func (s *clientSuite) serve(h http.HandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(h)
+	s.T().Cleanup(srv.Close)
+	return srv
+}
+
+func (s *clientSuite) TestCheckResponseSuccess() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.NoError(errxhttp.CheckResponse(resp))
+}
+
+func (s *clientSuite) TestCheckResponseWireBody() {
+	srv := s.serve(func(w http.ResponseWriter, r *http.Request) {
+		errxhttp.WriteError(w, r, errx.NewNotFound("invoice not found").WithDetail("invoice_id", "inv-1"))
+	})
+
+	resp, err := http.Get(srv.URL + "/invoices/inv-1")
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	err = errxhttp.CheckResponse(resp, errxhttp.WithService("billing"))
+	s.Require().Error(err)
+	s.True(errx.CodeIs(err, errx.CodeNotFound))
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal("invoice not found", e.Error())
+	s.Equal("billing", e.Source())
+	s.Equal("inv-1", e.Details()["invoice_id"])
+	s.Equal(http.StatusNotFound, e.Metadata()["status"])
+	s.Equal(http.MethodGet, e.Metadata()["method"])
+	s.False(e.IsRetryable())
+
+	// The body remains readable for the caller.
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Contains(string(data), "invoice not found")
+}
+
+func (s *clientSuite) TestCheckResponseErrorIDAndDebugToken() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", errxhttp.ContentTypeJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"code":"internal","message":"internal error","error_id":"01HZX","debug_token":"v1.abc"}`)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	var e *errx.Error
+	s.Require().ErrorAs(errxhttp.CheckResponse(resp), &e)
+	s.Equal("01HZX", e.Metadata()["error_id"])
+	s.Equal("v1.abc", e.Metadata()["debug_ref"])
+}
+
+func (s *clientSuite) TestDebugTokenIsLogged() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", errxhttp.ContentTypeJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"code":"internal","message":"internal error","debug_token":"v1.Zm9vYmFy"}`)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	var e *errx.Error
+	s.Require().ErrorAs(errxhttp.CheckResponse(resp), &e)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Error("upstream failed", "error", e)
+	s.Contains(buf.String(), "debug_ref:v1.Zm9vYmFy")
+	s.Contains(e.DebugMessage(), "v1.Zm9vYmFy")
+}
+
+func (s *clientSuite) TestCheckResponseProblemBody() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = io.WriteString(w, `{"type":"https://example.com/probs/stale","title":"Conflict","detail":"version mismatch","instance":"/orders/7","details":{"version":3}}`)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	e, ok := errx.As(errxhttp.CheckResponse(resp))
+	s.Require().True(ok)
+	s.Equal(errx.CodeAlreadyExists, e.Code())
+	s.Equal("version mismatch", e.Error())
+	s.InDelta(3, e.Details()["version"], 0)
+	s.Equal("https://example.com/probs/stale", e.Metadata()["problem_type"])
+	s.Equal("/orders/7", e.Metadata()["problem_instance"])
+}
+
+func (s *clientSuite) TestCheckResponseProblemCodeExtension() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", errxhttp.ContentTypeProblem)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"title":"Bad Request","code":"failed_precondition"}`)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	e, ok := errx.As(errxhttp.CheckResponse(resp))
+	s.Require().True(ok)
+	s.Equal(errx.CodeFailedPrecondition, e.Code())
+	s.Equal("Bad Request", e.Error())
+}
+
+func (s *clientSuite) TestCheckResponseUnrecognizedBody() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "upstream exploded", http.StatusServiceUnavailable)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	e, ok := errx.As(errxhttp.CheckResponse(resp))
+	s.Require().True(ok)
+	s.Equal(errx.CodeUnavailable, e.Code())
+	s.Equal("Service Unavailable", e.Error())
+	s.Contains(e.DebugMessage(), "upstream exploded")
+	s.True(e.IsRetryable())
+	s.Equal(resp.Request.URL.Host, e.Source())
+}
+
+func (s *clientSuite) TestCheckResponseRetryAfterSeconds() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	e, ok := errx.As(errxhttp.CheckResponse(resp))
+	s.Require().True(ok)
+	s.Equal(errx.CodeResourceExhausted, e.Code())
+	s.True(e.IsRetryable())
+	d, ok := e.RetryAfter()
+	s.True(ok)
+	s.Equal(30*time.Second, d)
+}
+
+func (s *clientSuite) TestCheckResponseRetryAfterDate() {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", now.Add(2*time.Minute).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	e, ok := errx.As(errxhttp.CheckResponse(resp, errxhttp.WithClock(func() time.Time { return now })))
+	s.Require().True(ok)
+	d, ok := e.RetryAfter()
+	s.True(ok)
+	s.Equal(2*time.Minute, d)
+}
+
+func (s *clientSuite) TestCheckResponseRetryAfterInvalid() {
+	for _, value := range []string{"soon", "-5"} {
+		s.Run(value, func() {
+			srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Retry-After", value)
+				w.WriteHeader(http.StatusTooManyRequests)
+			})
+
+			resp, err := http.Get(srv.URL)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			e, ok := errx.As(errxhttp.CheckResponse(resp))
+			s.Require().True(ok)
+			_, ok = e.RetryAfter()
+			s.False(ok)
+		})
+	}
+}
+
+func (s *clientSuite) TestTransport() {
+	srv := s.serve(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			_, _ = io.WriteString(w, "ok")
+			return
+		}
+		errxhttp.WriteError(w, r, errx.NewUnavailable("maintenance"))
+	})
+
+	client := &http.Client{Transport: &errxhttp.Transport{Service: "inventory"}}
+
+	resp, err := client.Get(srv.URL + "/ok")
+	s.Require().NoError(err)
+	s.Require().NoError(resp.Body.Close())
+
+	resp, err = client.Get(srv.URL + "/fail")
+	s.Nil(resp)
+	s.Require().Error(err)
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+	s.True(errx.IsRetryable(err))
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal("inventory", e.Source())
+	s.Equal("maintenance", e.Error())
+}
+
+func (s *clientSuite) TestTransportFollowsRedirects() {
+	srv := s.serve(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		_, _ = io.WriteString(w, "moved")
+	})
+
+	client := &http.Client{Transport: &errxhttp.Transport{}}
+	resp, err := client.Get(srv.URL + "/old")
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+	s.Equal("moved", string(body))
+	s.Equal("/new", resp.Request.URL.Path)
+}
+
+func (s *clientSuite) TestCheckResponseNotModified() {
+	srv := s.serve(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.NoError(errxhttp.CheckResponse(resp))
+}
+
+func (s *clientSuite) TestTransportBaseError() {
+	boom := errors.New("boom")
+	client := &http.Client{Transport: &errxhttp.Transport{
+		Base: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, boom }),
+	}}
+
+	_, err := client.Get("http://example.invalid")
+	s.ErrorIs(err, boom)
+	s.False(errx.Is(err))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func (s *clientSuite) TestCheckResponseRetryDelayRoundTrip() {
+	srv := s.serve(func(w http.ResponseWriter, r *http.Request) {
+		errxhttp.WriteError(w, r, errx.NewUnavailable("draining").WithRetryAfter(250*time.Millisecond))
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	err = errxhttp.CheckResponse(resp)
+	d, ok := errx.RetryDelay(err)
+	s.True(ok)
+	s.Equal(250*time.Millisecond, d, "the precise body value is preferred over the rounded header")
+	s.True(errx.IsRetryable(err))
+}
+
+func (s *clientSuite) TestCheckResponseReasonRoundTrip() {
+	srv := s.serve(func(w http.ResponseWriter, r *http.Request) {
+		errxhttp.WriteError(w, r, errx.NewFailedPrecondition("invite has expired").WithReason("INVITE_EXPIRED"))
+	})
+
+	resp, err := http.Get(srv.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	err = errxhttp.CheckResponse(resp)
+	s.ErrorIs(err, errx.NewFailedPrecondition("sentinel").WithReason("INVITE_EXPIRED"))
+}
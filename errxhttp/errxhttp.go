@@ -0,0 +1,109 @@
+// Package errxhttp maps errx errors to and from HTTP responses.
+//
+// On the server side, [WriteError] renders an error as either the errx wire
+// format or an RFC 9457 problem document, choosing the HTTP status from the
+// error's code.
+//
+// On the client side, [CheckResponse] and [Transport] turn 4xx and 5xx responses
+// back into *errx.Error values so callers can use errx.CodeIs, errx.CodeOf and
+// errx.IsRetryable on remote failures exactly as they would on local ones:
+//
+//	client := &http.Client{Transport: &errxhttp.Transport{Service: "billing"}}
+//
+//	resp, err := client.Get("https://billing.internal/invoices/42")
+//	if errx.CodeIs(err, errx.CodeNotFound) {
+//	    // handle missing invoice
+//	}
+package errxhttp
+
+import (
+	"net/http"
+
+	"github.com/bjaus/errx"
+)
+
+// Content types understood by the encoder and decoder.
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
+)
+
+// StatusCode returns the HTTP status code conventionally used for code.
+// The mapping follows the Connect protocol; unknown codes map to 500.
+func StatusCode(code errx.Code) int {
+	switch code {
+	case errx.CodeCanceled:
+		return 499
+	case errx.CodeInvalidArgument, errx.CodeFailedPrecondition, errx.CodeOutOfRange:
+		return http.StatusBadRequest
+	case errx.CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case errx.CodeNotFound:
+		return http.StatusNotFound
+	case errx.CodeAlreadyExists, errx.CodeAborted:
+		return http.StatusConflict
+	case errx.CodePermissionDenied:
+		return http.StatusForbidden
+	case errx.CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case errx.CodeUnimplemented:
+		return http.StatusNotImplemented
+	case errx.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case errx.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CodeFromStatus returns the errx code that best describes an HTTP status.
+// Statuses below 400 and unrecognized statuses map to CodeUnknown; unrecognized
+// 4xx statuses map to CodeInvalidArgument and unrecognized 5xx statuses to CodeInternal.
+func CodeFromStatus(status int) errx.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return errx.CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return errx.CodeUnauthenticated
+	case http.StatusForbidden:
+		return errx.CodePermissionDenied
+	case http.StatusNotFound:
+		return errx.CodeNotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return errx.CodeDeadlineExceeded
+	case http.StatusConflict:
+		return errx.CodeAlreadyExists
+	case http.StatusPreconditionFailed:
+		return errx.CodeFailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return errx.CodeOutOfRange
+	case http.StatusTooManyRequests:
+		return errx.CodeResourceExhausted
+	case 499:
+		return errx.CodeCanceled
+	case http.StatusNotImplemented:
+		return errx.CodeUnimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return errx.CodeUnavailable
+	}
+	switch {
+	case status >= 400 && status < 500:
+		return errx.CodeInvalidArgument
+	case status >= 500 && status < 600:
+		return errx.CodeInternal
+	default:
+		return errx.CodeUnknown
+	}
+}
+
+// retryableStatus reports whether a response status indicates the request may
+// succeed if sent again later.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	default:
+		return false
+	}
+}
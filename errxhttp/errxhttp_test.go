@@ -0,0 +1,76 @@
+package errxhttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+)
+
+type statusSuite struct {
+	suite.Suite
+}
+
+func TestStatusSuite(t *testing.T) {
+	suite.Run(t, new(statusSuite))
+}
+
+func (s *statusSuite) TestStatusCode() {
+	tests := map[errx.Code]int{
+		errx.CodeUnknown:            http.StatusInternalServerError,
+		errx.CodeCanceled:           499,
+		errx.CodeInvalidArgument:    http.StatusBadRequest,
+		errx.CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+		errx.CodeNotFound:           http.StatusNotFound,
+		errx.CodeAlreadyExists:      http.StatusConflict,
+		errx.CodePermissionDenied:   http.StatusForbidden,
+		errx.CodeResourceExhausted:  http.StatusTooManyRequests,
+		errx.CodeFailedPrecondition: http.StatusBadRequest,
+		errx.CodeAborted:            http.StatusConflict,
+		errx.CodeOutOfRange:         http.StatusBadRequest,
+		errx.CodeUnimplemented:      http.StatusNotImplemented,
+		errx.CodeInternal:           http.StatusInternalServerError,
+		errx.CodeUnavailable:        http.StatusServiceUnavailable,
+		errx.CodeDataLoss:           http.StatusInternalServerError,
+		errx.CodeUnauthenticated:    http.StatusUnauthorized,
+	}
+
+	s.Len(tests, len(errx.CodeValues()))
+	for code, status := range tests {
+		s.Run(code.String(), func() {
+			s.Equal(status, errxhttp.StatusCode(code))
+		})
+	}
+}
+
+func (s *statusSuite) TestCodeFromStatus() {
+	tests := map[int]errx.Code{
+		http.StatusOK:                           errx.CodeUnknown,
+		http.StatusBadRequest:                   errx.CodeInvalidArgument,
+		http.StatusUnauthorized:                 errx.CodeUnauthenticated,
+		http.StatusForbidden:                    errx.CodePermissionDenied,
+		http.StatusNotFound:                     errx.CodeNotFound,
+		http.StatusRequestTimeout:               errx.CodeDeadlineExceeded,
+		http.StatusConflict:                     errx.CodeAlreadyExists,
+		http.StatusPreconditionFailed:           errx.CodeFailedPrecondition,
+		http.StatusRequestedRangeNotSatisfiable: errx.CodeOutOfRange,
+		http.StatusTeapot:                       errx.CodeInvalidArgument,
+		http.StatusTooManyRequests:              errx.CodeResourceExhausted,
+		499:                                     errx.CodeCanceled,
+		http.StatusInternalServerError:          errx.CodeInternal,
+		http.StatusNotImplemented:               errx.CodeUnimplemented,
+		http.StatusBadGateway:                   errx.CodeUnavailable,
+		http.StatusServiceUnavailable:           errx.CodeUnavailable,
+		http.StatusGatewayTimeout:               errx.CodeDeadlineExceeded,
+		http.StatusHTTPVersionNotSupported:      errx.CodeInternal,
+	}
+
+	for status, code := range tests {
+		s.Run(http.StatusText(status), func() {
+			s.Equal(code, errxhttp.CodeFromStatus(status))
+		})
+	}
+}
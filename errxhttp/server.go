@@ -0,0 +1,183 @@
+package errxhttp
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bjaus/errx"
+)
+
+// TokenSealer seals the internal details of an error into an opaque token;
+// see package github.com/bjaus/errx/debugtoken.
+type TokenSealer interface {
+	Seal(e *errx.Error) (string, error)
+}
+
+// WriteOption configures WriteError.
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	sealer TokenSealer
+}
+
+// WithDebugToken adds a debug token sealed by sealer to error responses, as the
+// "debug_token" member. Responses are written without a token if sealing fails.
+func WithDebugToken(sealer TokenSealer) WriteOption {
+	return func(c *writeConfig) {
+		c.sealer = sealer
+	}
+}
+
+// WriteError writes err to w as a JSON error response. The status code is
+// derived from the error's code with [StatusCode].
+//
+// Errors that are not *errx.Error are reported as internal errors with a generic
+// message so that implementation details never reach the client. Only the
+// client-safe message and details are written.
+//
+// If the error carries a retry delay hint (see errx.RetryDelay), it is also sent
+// as a Retry-After header in whole seconds, rounded up.
+//
+// If the request's Accept header prefers application/problem+json, the body is a
+// [Problem]; otherwise it is a [Body]. r may be nil.
+//
+// If the error has a message key and a Localizer is set with errx.SetLocalizer,
+// the message is translated into the most preferred language of the request's
+// Accept-Language header that has a translation, and that language is sent as
+// the Content-Language header. Without a translation the message is unchanged.
+//
+// WriteError runs the errx.OnCheck hooks on err before writing it.
+func WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...WriteOption) {
+	errx.Check(err)
+
+	var cfg writeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	e := errx.Ensure(err, errx.CodeInternal, "internal error")
+	if e == nil {
+		e = errx.NewInternal("internal error")
+	}
+	status := StatusCode(e.Code())
+
+	message, lang := localize(w, r, e)
+
+	var token string
+	if cfg.sealer != nil {
+		if t, err := cfg.sealer.Seal(e); err == nil {
+			token = t
+		}
+	}
+
+	var payload any
+	contentType := ContentTypeJSON
+	if r != nil && acceptsProblem(r.Header.Get("Accept")) {
+		contentType = ContentTypeProblem
+		p := NewProblem(e)
+		p.Detail = message
+		p.DebugToken = token
+		payload = p
+	} else {
+		b := NewBody(e)
+		b.Message = message
+		b.DebugToken = token
+		payload = b
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+	if d, ok := errx.RetryDelay(e); ok {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// localize returns the redacted client message for e in the language negotiated
+// from r's Accept-Language header, and that language. If no translation is
+// found, it returns the redacted e.Error() and an empty language. It marks the
+// response as varying by Accept-Language when the outcome depends on it.
+func localize(w http.ResponseWriter, r *http.Request, e *errx.Error) (string, string) {
+	red := errx.DefaultRedactor().Client()
+	l := errx.DefaultLocalizer()
+	if l == nil || e.MessageKey() == "" {
+		return red.Message(e), ""
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	if r == nil {
+		return red.Message(e), ""
+	}
+	for _, lang := range ParseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if msg, ok := red.Localize(e, l, lang); ok {
+			return msg, lang
+		}
+	}
+	return red.Message(e), ""
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header in
+// order of preference. Tags with equal weight keep their order; the "*" wildcard,
+// tags with q=0 and malformed entries are omitted.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || v < 0 || v > 1 {
+				v = 0
+			}
+			q = v
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	slices.SortStableFunc(tags, func(a, b weighted) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	langs := make([]string, len(tags))
+	for i, t := range tags {
+		langs[i] = t.tag
+	}
+	return langs
+}
+
+// acceptsProblem reports whether an Accept header explicitly lists the problem
+// details media type.
+func acceptsProblem(accept string) bool {
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType == ContentTypeProblem && params["q"] != "0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,341 @@
+package errxhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+)
+
+type serverSuite struct {
+	suite.Suite
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(serverSuite))
+}
+
+func (s *serverSuite) TestWriteErrorBody() {
+	err := errx.NewNotFound("user not found").
+		WithDetail("user_id", "u-1").
+		WithMeta("query", "SELECT 1").
+		WithDebug("no rows")
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodGet, "/users/u-1", nil), err)
+
+	s.Equal(http.StatusNotFound, rec.Code)
+	s.Equal(errxhttp.ContentTypeJSON, rec.Header().Get("Content-Type"))
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("not_found", body.Code)
+	s.Equal("user not found", body.Message)
+	s.Equal(map[string]any{"user_id": "u-1"}, body.Details)
+	s.NotContains(rec.Body.String(), "SELECT")
+	s.NotContains(rec.Body.String(), "no rows")
+}
+
+func (s *serverSuite) TestWriteErrorRunsCheckHooks() {
+	var checked []*errx.Error
+	defer errx.OnCheck(func(e *errx.Error) { checked = append(checked, e) })()
+
+	err := errx.NewNotFound("user not found").WithSource("users")
+	errxhttp.WriteError(httptest.NewRecorder(), nil, err)
+	s.Equal([]*errx.Error{err}, checked)
+}
+
+func (s *serverSuite) TestWriteErrorProblem() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, errx.NewPermissionDenied("access denied"))
+
+	s.Equal(http.StatusForbidden, rec.Code)
+	s.Equal(errxhttp.ContentTypeProblem, rec.Header().Get("Content-Type"))
+
+	var p errxhttp.Problem
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &p))
+	s.Equal("about:blank", p.Type)
+	s.Equal("Forbidden", p.Title)
+	s.Equal(http.StatusForbidden, p.Status)
+	s.Equal("access denied", p.Detail)
+	s.Equal("permission_denied", p.Code)
+}
+
+func (s *serverSuite) TestWriteErrorProblemRejected() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/problem+json;q=0")
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, errx.NewPermissionDenied("access denied"))
+
+	s.Equal(errxhttp.ContentTypeJSON, rec.Header().Get("Content-Type"))
+}
+
+func (s *serverSuite) TestWriteErrorPlainError() {
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, nil, errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+
+	s.Equal(http.StatusInternalServerError, rec.Code)
+	s.NotContains(rec.Body.String(), "10.0.0.1")
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("internal", body.Code)
+	s.Equal("internal error", body.Message)
+}
+
+func (s *serverSuite) TestWriteErrorNil() {
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, nil, nil)
+
+	s.Equal(http.StatusInternalServerError, rec.Code)
+}
+
+func (s *serverSuite) TestWriteErrorRetryAfter() {
+	err := errx.NewResourceExhausted("slow down").WithRetryAfter(1500 * time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, nil, err)
+
+	s.Equal(http.StatusTooManyRequests, rec.Code)
+	s.Equal("2", rec.Header().Get("Retry-After"))
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("1.5s", body.RetryDelay)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", errxhttp.ContentTypeProblem)
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+
+	var p errxhttp.Problem
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &p))
+	s.Equal("1.5s", p.RetryDelay)
+}
+
+func (s *serverSuite) TestWriteErrorNoRetryAfter() {
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, nil, errx.NewUnavailable("down").WithRetryable())
+
+	s.Empty(rec.Header().Get("Retry-After"))
+	s.NotContains(rec.Body.String(), "retry_delay")
+}
+
+// translations is a fixed errx.Localizer for negotiation tests.
+type translations map[string]string // by language
+
+func (t translations) Localize(lang, _ string, params map[string]any) (string, bool) {
+	msg, ok := t[lang]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf(msg, params["user_id"]), true
+}
+
+func (s *serverSuite) TestWriteErrorNegotiatesLanguage() {
+	errx.SetLocalizer(translations{"de": "Benutzer %v nicht gefunden", "fr": "utilisateur %v introuvable"})
+	defer errx.SetLocalizer(nil)
+
+	err := errx.NewNotFound("user not found").
+		WithDetail("user_id", "u-1").
+		WithMessageKey("user.not_found", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es;q=0.9, fr;q=0.5, de")
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("Benutzer u-1 nicht gefunden", body.Message)
+	s.Equal("de", rec.Header().Get("Content-Language"))
+	s.Equal("Accept-Language", rec.Header().Get("Vary"))
+
+	req.Header.Set("Accept-Language", "es, fr;q=0.5")
+	req.Header.Set("Accept", errxhttp.ContentTypeProblem)
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+
+	var problem errxhttp.Problem
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	s.Equal("utilisateur u-1 introuvable", problem.Detail)
+	s.Equal("fr", rec.Header().Get("Content-Language"))
+
+	req.Header.Set("Accept-Language", "ja")
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	s.Equal("user not found", problem.Detail, "without a translation the message is unchanged")
+	s.Empty(rec.Header().Get("Content-Language"))
+}
+
+func (s *serverSuite) TestWriteErrorWithoutMessageKeyIsNotLocalized() {
+	errx.SetLocalizer(translations{"de": "übersetzt"})
+	defer errx.SetLocalizer(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, errx.NewNotFound("user not found"))
+
+	s.Empty(rec.Header().Get("Content-Language"))
+	s.Empty(rec.Header().Get("Vary"))
+}
+
+func (s *serverSuite) TestParseAcceptLanguage() {
+	tests := map[string][]string{
+		"":                             {},
+		"de":                           {"de"},
+		"en-US,en;q=0.9,de;q=0.8":      {"en-US", "en", "de"},
+		"fr;q=0.5, es, *;q=0.1":        {"es", "fr"},
+		"de;q=0, nl;Q=0.7, it;q=bogus": {"nl"},
+		"a;q=0.5, b;q=0.5, c":          {"c", "a", "b"},
+	}
+	for header, want := range tests {
+		s.Equal(want, errxhttp.ParseAcceptLanguage(header), header)
+	}
+}
+
+func (s *serverSuite) TestWriteErrorRedactsDetails() {
+	err := errx.NewInvalidArgument("invalid login").
+		WithDetail("username", "bob").
+		WithDetail("password", "hunter2").
+		WithDetail("pin", errx.Mask(1234))
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodPost, "/login", nil), err)
+
+	s.NotContains(rec.Body.String(), "hunter2")
+	s.NotContains(rec.Body.String(), "1234")
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal(map[string]any{"username": "bob", "password": errx.RedactedText, "pin": errx.RedactedText}, body.Details)
+}
+
+func (s *serverSuite) TestWriteErrorKeepsClientMessage() {
+	err := errx.NewNotFound("order 4111111111111111 for bob@example.com not found").
+		WithDetail("contact", "bob@example.com")
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, nil, err)
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal(err.Error(), body.Message, "scrubbers do not apply to client messages by default")
+	s.Equal("bob@example.com", body.Details["contact"])
+
+	errx.SetRedactor(errx.MaskedRedactor(errx.WithClientScrubbing()))
+	defer errx.SetRedactor(errx.MaskedRedactor())
+	s.Equal("order [CARD] for [EMAIL] not found", errxhttp.NewBody(err).Message)
+	s.Equal("[EMAIL]", errxhttp.NewProblem(err).Details["contact"])
+}
+
+func (s *serverSuite) TestWriteErrorRedactsTemplateMessage() {
+	err := errx.NewTemplate(errx.CodeInvalidArgument, "reset token {token} is invalid").
+		WithDetail("token", "s3cr3t-tok")
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodPost, "/reset", nil), err)
+	s.NotContains(rec.Body.String(), "s3cr3t-tok")
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("reset token [REDACTED] is invalid", body.Message)
+
+	req := httptest.NewRequest(http.MethodPost, "/reset", nil)
+	req.Header.Set("Accept", errxhttp.ContentTypeProblem)
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+	s.NotContains(rec.Body.String(), "s3cr3t-tok")
+	s.Equal("reset token [REDACTED] is invalid", errxhttp.NewProblem(err).Detail)
+}
+
+func (s *serverSuite) TestWriteErrorRedactsLocalizedMessage() {
+	errx.SetLocalizer(translations{"de": "Benutzer %v nicht gefunden"})
+	defer errx.SetLocalizer(nil)
+
+	err := errx.NewNotFound("user not found").
+		WithMessageKey("user.not_found", map[string]any{"user_id": errx.Mask("u-1")})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, err)
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("Benutzer [REDACTED] nicht gefunden", body.Message)
+}
+
+type stubSealer struct {
+	token string
+	err   error
+}
+
+func (s stubSealer) Seal(e *errx.Error) (string, error) {
+	return s.token + ":" + e.Code().String(), s.err
+}
+
+func (s *serverSuite) TestWriteErrorDebugToken() {
+	opt := errxhttp.WithDebugToken(stubSealer{token: "v1.tok"})
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("db down"), opt)
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("v1.tok:internal", body.DebugToken)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", errxhttp.ContentTypeProblem)
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, errx.NewNotFound("user not found"), opt)
+
+	var p errxhttp.Problem
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &p))
+	s.Equal("v1.tok:not_found", p.DebugToken)
+}
+
+func (s *serverSuite) TestWriteErrorDebugTokenFailure() {
+	opt := errxhttp.WithDebugToken(stubSealer{err: errors.New("no entropy")})
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errx.NewNotFound("user not found"), opt)
+
+	s.Equal(http.StatusNotFound, rec.Code)
+	s.NotContains(rec.Body.String(), "debug_token")
+}
+
+func (s *serverSuite) TestWriteErrorID() {
+	errx.SetIDGenerator(func(time.Time) string { return "01HZX" })
+	defer errx.SetIDGenerator(nil)
+
+	rec := httptest.NewRecorder()
+	errxhttp.WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errx.NewNotFound("user not found"))
+
+	var body errxhttp.Body
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &body))
+	s.Equal("01HZX", body.ErrorID)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", errxhttp.ContentTypeProblem)
+	rec = httptest.NewRecorder()
+	errxhttp.WriteError(rec, req, errx.NewNotFound("user not found"))
+
+	var p errxhttp.Problem
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &p))
+	s.Equal("01HZX", p.ErrorID)
+}
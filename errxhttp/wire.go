@@ -0,0 +1,192 @@
+package errxhttp
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// Body is the errx wire representation of an error in a JSON response body.
+// Only client-safe data is included.
+type Body struct {
+	Code       string         `json:"code"`
+	Reason     string         `json:"reason,omitempty"`
+	Message    string         `json:"message"`
+	ErrorID    string         `json:"error_id,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	RetryDelay string         `json:"retry_delay,omitempty"`
+	DebugToken string         `json:"debug_token,omitempty"`
+}
+
+// Problem is an RFC 9457 problem details document. The errx code, reason and
+// client-safe details are carried as the "code", "reason" and "details" extension
+// members, the error instance ID as "error_id", a retry delay hint as
+// "retry_delay", and a debug token as "debug_token".
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	ErrorID    string         `json:"error_id,omitempty"`
+	Code       string         `json:"code,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	RetryDelay string         `json:"retry_delay,omitempty"`
+	DebugToken string         `json:"debug_token,omitempty"`
+}
+
+// NewBody returns the wire body for e. The message and details are redacted
+// with the client policy of errx.DefaultRedactor (see errx.Redactor.Client).
+func NewBody(e *errx.Error) Body {
+	r := errx.DefaultRedactor().Client()
+	return Body{
+		Code:       e.Code().String(),
+		Reason:     e.Reason(),
+		Message:    r.Message(e),
+		ErrorID:    e.ID(),
+		Details:    r.Map(e.Details()),
+		RetryDelay: formatRetryDelay(e),
+	}
+}
+
+// NewProblem returns the problem document for e. The detail and details are
+// redacted with the client policy of errx.DefaultRedactor.
+func NewProblem(e *errx.Error) Problem {
+	r := errx.DefaultRedactor().Client()
+	status := StatusCode(e.Code())
+	return Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     r.Message(e),
+		ErrorID:    e.ID(),
+		Code:       e.Code().String(),
+		Reason:     e.Reason(),
+		Details:    r.Map(e.Details()),
+		RetryDelay: formatRetryDelay(e),
+	}
+}
+
+// formatRetryDelay renders the retry delay hint in e's tree in the protobuf JSON
+// duration style ("1.5s"), or returns "" if there is none.
+func formatRetryDelay(e *errx.Error) string {
+	d, ok := errx.RetryDelay(e)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// applyRetryDelay sets the retry delay hint on e from a wire value, ignoring
+// values that do not parse.
+func applyRetryDelay(e *errx.Error, value string) {
+	if value == "" {
+		return
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		e.WithRetryAfter(d)
+	}
+}
+
+// applyErrorID records the instance ID of the remote error as the "error_id"
+// metadata, so local logs can be correlated with the remote service's logs.
+func applyErrorID(e *errx.Error, id string) {
+	if id != "" {
+		e.WithMeta("error_id", id)
+	}
+}
+
+// applyDebugToken records a debug token from a wire value as the "debug_ref"
+// metadata, so it can be logged and passed on to support. The token is
+// encrypted, so the key avoids the word "token", whose values are redacted by
+// errx.MaskedRedactor.
+func applyDebugToken(e *errx.Error, token string) {
+	if token != "" {
+		e.WithMeta("debug_ref", token)
+	}
+}
+
+// decodeBody builds an error from a response body in either the errx wire
+// format or the problem details format. It returns false if the body is not
+// recognized as either, in which case the caller should fall back to the status.
+func decodeBody(contentType string, data []byte, status int) (*errx.Error, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case ContentTypeProblem:
+		return decodeProblem(data, status)
+	case ContentTypeJSON:
+		if e, ok := decodeWire(data, status); ok {
+			return e, true
+		}
+		return decodeProblem(data, status)
+	default:
+		return nil, false
+	}
+}
+
+// decodeWire decodes the errx wire format. A body without a code is not
+// considered an errx body.
+func decodeWire(data []byte, status int) (*errx.Error, bool) {
+	var body Body
+	if err := json.Unmarshal(data, &body); err != nil || body.Code == "" {
+		return nil, false
+	}
+	code, ok := errx.ParseCode(body.Code)
+	if !ok {
+		code = CodeFromStatus(status)
+	}
+	message := body.Message
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	e := errx.New(code, message).WithReason(body.Reason)
+	for k, v := range body.Details {
+		e.WithDetail(k, v)
+	}
+	applyRetryDelay(e, body.RetryDelay)
+	applyErrorID(e, body.ErrorID)
+	applyDebugToken(e, body.DebugToken)
+	return e, true
+}
+
+// decodeProblem decodes a problem details document. A body with neither a title,
+// a detail nor a code is not considered a problem document.
+func decodeProblem(data []byte, status int) (*errx.Error, bool) {
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, false
+	}
+	if p.Title == "" && p.Detail == "" && p.Code == "" {
+		return nil, false
+	}
+	code, ok := errx.ParseCode(p.Code)
+	if !ok {
+		code = CodeFromStatus(status)
+	}
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	e := errx.New(code, message).WithReason(p.Reason)
+	for k, v := range p.Details {
+		e.WithDetail(k, v)
+	}
+	applyRetryDelay(e, p.RetryDelay)
+	applyErrorID(e, p.ErrorID)
+	applyDebugToken(e, p.DebugToken)
+	if p.Type != "" && p.Type != "about:blank" {
+		e.WithMeta("problem_type", p.Type)
+	}
+	if p.Instance != "" {
+		e.WithMeta("problem_instance", p.Instance)
+	}
+	return e, true
+}
@@ -0,0 +1,171 @@
+// Package errxotel records errx errors on OpenTelemetry spans and attaches
+// trace context to errors.
+//
+// span.RecordError only keeps an error's message. RecordError also records the
+// errx code, reason, source, tags and client-safe details, using the semantic
+// convention attributes for errors and exceptions where they exist:
+//
+//	ctx, span := tracer.Start(ctx, "GetUser")
+//	defer span.End()
+//
+//	user, err := repo.Get(ctx, id)
+//	if err != nil {
+//	    errxotel.RecordError(span, err)
+//	    return nil, errxotel.WithTraceFromContext(ctx, errx.Ensure(err, errx.CodeInternal, "get user"))
+//	}
+//
+// Client errors, as classified by errx.FaultOf, such as not_found or
+// invalid_argument, are recorded as exceptions but leave the span status unset,
+// as the HTTP and RPC semantic conventions require for server spans. Every other
+// error, including errors of unknown fault, sets the status to Error.
+//
+// errxotel is a separate module, so the errx module does not depend on
+// OpenTelemetry.
+package errxotel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bjaus/errx"
+)
+
+// Attribute keys. error.type and the exception attributes follow the
+// OpenTelemetry semantic conventions; the others are specific to errx.
+const (
+	ErrorTypeKey           = attribute.Key("error.type")
+	ExceptionTypeKey       = attribute.Key("exception.type")
+	ExceptionMessageKey    = attribute.Key("exception.message")
+	ExceptionStacktraceKey = attribute.Key("exception.stacktrace")
+	ReasonKey              = attribute.Key("errx.reason")
+	SourceKey              = attribute.Key("errx.source")
+	TagsKey                = attribute.Key("errx.tags")
+	IDKey                  = attribute.Key("errx.id")
+	FingerprintKey         = attribute.Key("errx.fingerprint")
+	RetryableKey           = attribute.Key("errx.retryable")
+
+	// DetailKeyPrefix prefixes the keys of client-safe details, as in
+	// "errx.detail.user_id".
+	DetailKeyPrefix = "errx.detail."
+)
+
+// Metadata keys set by WithTraceFromContext.
+const (
+	TraceIDMeta = "trace_id"
+	SpanIDMeta  = "span_id"
+)
+
+// exceptionType is the exception.type of *errx.Error values.
+const exceptionType = "*errx.Error"
+
+// Attributes returns the span attributes describing err. Errors that are not
+// *errx.Error are described by their Go type and message, with error.type
+// "unknown". Details are redacted with errx.DefaultRedactor.
+func Attributes(err error) []attribute.KeyValue {
+	if err == nil {
+		return nil
+	}
+	e, ok := errx.As(err)
+	if !ok {
+		return []attribute.KeyValue{
+			ErrorTypeKey.String(errx.CodeUnknown.String()),
+			ExceptionTypeKey.String(fmt.Sprintf("%T", err)),
+			ExceptionMessageKey.String(errx.DefaultRedactor().Message(err)),
+		}
+	}
+
+	r := errx.DefaultRedactor()
+	attrs := []attribute.KeyValue{
+		ErrorTypeKey.String(errx.CodeOf(err).String()),
+		ExceptionTypeKey.String(exceptionType),
+		ExceptionMessageKey.String(r.Message(err)),
+		FingerprintKey.String(errx.Fingerprint(err)),
+		RetryableKey.Bool(errx.IsRetryable(err)),
+	}
+	if st := e.FormatStackTrace(); st != "" {
+		attrs = append(attrs, ExceptionStacktraceKey.String(st))
+	}
+	if v := e.Reason(); v != "" {
+		attrs = append(attrs, ReasonKey.String(v))
+	}
+	if v := e.Source(); v != "" {
+		attrs = append(attrs, SourceKey.String(v))
+	}
+	if v := e.Tags(); len(v) > 0 {
+		attrs = append(attrs, TagsKey.StringSlice(v))
+	}
+	if v := e.ID(); v != "" {
+		attrs = append(attrs, IDKey.String(v))
+	}
+	for k, v := range r.Map(e.Details()) {
+		attrs = append(attrs, detailAttribute(DetailKeyPrefix+k, v))
+	}
+	return attrs
+}
+
+// detailAttribute converts a detail value to an attribute, keeping basic types.
+func detailAttribute(key string, v any) attribute.KeyValue {
+	k := attribute.Key(key)
+	switch v := v.(type) {
+	case string:
+		return k.String(v)
+	case bool:
+		return k.Bool(v)
+	case int:
+		return k.Int(v)
+	case int64:
+		return k.Int64(v)
+	case float64:
+		return k.Float64(v)
+	case []string:
+		return k.StringSlice(v)
+	default:
+		return k.String(fmt.Sprint(v))
+	}
+}
+
+// IsServerFault reports whether err counts against the service: whether it is
+// not nil and errx.FaultOf does not attribute it to the client. Errors of
+// unknown fault, such as most non-errx errors, count as server faults.
+func IsServerFault(err error) bool {
+	return err != nil && errx.FaultOf(err) != errx.FaultClient
+}
+
+// RecordError records err on span as an exception event with Attributes and, if
+// it is a server fault, sets the span status to Error with the error's message.
+// Nil errors are ignored.
+func RecordError(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	opts = append([]trace.EventOption{trace.WithAttributes(Attributes(err)...)}, opts...)
+	span.AddEvent("exception", opts...)
+	span.SetAttributes(ErrorTypeKey.String(errx.CodeOf(err).String()))
+	SetStatus(span, err)
+}
+
+// SetStatus sets the span status to Error if err is a server fault, and leaves
+// it unchanged otherwise.
+func SetStatus(span trace.Span, err error) {
+	if IsServerFault(err) {
+		span.SetStatus(codes.Error, errx.DefaultRedactor().Message(err))
+	}
+}
+
+// WithTraceFromContext records the trace and span IDs of the span in ctx as the
+// "trace_id" and "span_id" metadata of e, so logs of the error link to the
+// trace. It returns e, and leaves it unchanged if ctx has no valid span context.
+func WithTraceFromContext(ctx context.Context, e *errx.Error) *errx.Error {
+	if e == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return e
+	}
+	return e.WithMeta(TraceIDMeta, sc.TraceID().String()).WithMeta(SpanIDMeta, sc.SpanID().String())
+}
@@ -0,0 +1,132 @@
+package errxotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxotel"
+)
+
+type otelSuite struct {
+	suite.Suite
+	exporter *tracetest.InMemoryExporter
+	tracer   trace.Tracer
+}
+
+func TestOtelSuite(t *testing.T) {
+	suite.Run(t, new(otelSuite))
+}
+
+func (s *otelSuite) SetupTest() {
+	s.exporter = tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(s.exporter))
+	s.T().Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	s.tracer = provider.Tracer("errxotel_test")
+}
+
+// record records err on a new span and returns the exported span.
+func (s *otelSuite) record(err error) tracetest.SpanStub {
+	_, span := s.tracer.Start(context.Background(), "op")
+	errxotel.RecordError(span, err)
+	span.End()
+
+	spans := s.exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	return spans[0]
+}
+
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func (s *otelSuite) TestServerFault() {
+	err := errx.WrapInternal(errors.New("connection refused"), "database unavailable").
+		WithReason("DB_DOWN").
+		WithSource("orders").
+		WithTags("db").
+		WithDetail("shard", 3).
+		WithDetail("token", "t-1")
+
+	span := s.record(err)
+	s.Equal(codes.Error, span.Status.Code)
+	s.Equal("database unavailable", span.Status.Description)
+	s.Equal("internal", attrs(span.Attributes)[errxotel.ErrorTypeKey].AsString())
+
+	s.Require().Len(span.Events, 1)
+	s.Equal("exception", span.Events[0].Name)
+	a := attrs(span.Events[0].Attributes)
+	s.Equal("internal", a[errxotel.ErrorTypeKey].AsString())
+	s.Equal("*errx.Error", a[errxotel.ExceptionTypeKey].AsString())
+	s.Equal("database unavailable", a[errxotel.ExceptionMessageKey].AsString())
+	s.Contains(a[errxotel.ExceptionStacktraceKey].AsString(), "TestServerFault")
+	s.Equal("DB_DOWN", a[errxotel.ReasonKey].AsString())
+	s.Equal("orders", a[errxotel.SourceKey].AsString())
+	s.Equal([]string{"db"}, a[errxotel.TagsKey].AsStringSlice())
+	s.Equal(errx.Fingerprint(err), a[errxotel.FingerprintKey].AsString())
+	s.Equal(int64(3), a["errx.detail.shard"].AsInt64())
+	s.Equal(errx.RedactedText, a["errx.detail.token"].AsString())
+}
+
+func (s *otelSuite) TestClientErrorLeavesStatusUnset() {
+	span := s.record(errx.NewNotFound("user not found"))
+	s.Equal(codes.Unset, span.Status.Code)
+	s.Require().Len(span.Events, 1)
+	s.Equal("not_found", attrs(span.Events[0].Attributes)[errxotel.ErrorTypeKey].AsString())
+}
+
+func (s *otelSuite) TestPlainError() {
+	span := s.record(errors.New("boom"))
+	s.Equal(codes.Error, span.Status.Code)
+	a := attrs(span.Events[0].Attributes)
+	s.Equal("unknown", a[errxotel.ErrorTypeKey].AsString())
+	s.Equal("*errors.errorString", a[errxotel.ExceptionTypeKey].AsString())
+	s.Equal("boom", a[errxotel.ExceptionMessageKey].AsString())
+}
+
+func (s *otelSuite) TestNilError() {
+	span := s.record(nil)
+	s.Empty(span.Events)
+	s.Equal(codes.Unset, span.Status.Code)
+	s.Nil(errxotel.Attributes(nil))
+}
+
+func (s *otelSuite) TestIsServerFault() {
+	s.True(errxotel.IsServerFault(errx.NewUnavailable("down")))
+	s.True(errxotel.IsServerFault(errx.Wrap(errx.NewNotFound("gone"), errx.CodeInternal, "lookup")))
+	s.True(errxotel.IsServerFault(context.DeadlineExceeded))
+	s.True(errxotel.IsServerFault(errors.New("boom")))
+	s.True(errxotel.IsServerFault(errx.New(errx.CodeUnknown, "boom")))
+	s.True(errxotel.IsServerFault(errx.NewResourceExhausted("slow down")))
+	s.True(errxotel.IsServerFault(errx.NewAborted("conflict")))
+	s.True(errxotel.IsServerFault(errx.Op(errors.New("refused"), "db.Query")))
+	s.False(errxotel.IsServerFault(errx.NewInvalidArgument("bad")))
+	s.False(errxotel.IsServerFault(errx.NewPermissionDenied("no")))
+	s.False(errxotel.IsServerFault(nil))
+}
+
+func (s *otelSuite) TestWithTraceFromContext() {
+	ctx, span := s.tracer.Start(context.Background(), "op")
+	defer span.End()
+
+	err := errxotel.WithTraceFromContext(ctx, errx.NewInternal("boom"))
+	sc := span.SpanContext()
+	s.Equal(sc.TraceID().String(), err.Metadata()[errxotel.TraceIDMeta])
+	s.Equal(sc.SpanID().String(), err.Metadata()[errxotel.SpanIDMeta])
+
+	plain := errxotel.WithTraceFromContext(context.Background(), errx.NewInternal("boom"))
+	s.Empty(plain.Metadata())
+	s.Nil(errxotel.WithTraceFromContext(ctx, nil))
+}
@@ -0,0 +1,316 @@
+// Package errxsentry reports errx errors to Sentry, or to any collector that
+// accepts Sentry envelopes, without depending on the Sentry SDK.
+//
+// NewEvent converts an error into a Sentry event: every error in the cause
+// chain becomes an exception with its own stack trace, the code, reason and
+// tags become Sentry tags, the source becomes the logger, redacted metadata
+// becomes extra data, and errx.Fingerprint groups the events.
+//
+// A Sink sends the errors of a report.Reporter as events to the project
+// identified by a DSN. The reporter queues errors and writes them in batches
+// off the caller's goroutine, and the sink retries each envelope with
+// exponential backoff, honoring Retry-After:
+//
+//	sentry, err := errxsentry.NewSink("https://public@sentry.example.com/42",
+//	    errxsentry.WithEventOptions(
+//	        errxsentry.WithEnvironment("production"),
+//	        errxsentry.WithRelease(version),
+//	    ),
+//	)
+//	...
+//	r := report.New(report.WithSink(sentry, report.OnlyCodes(errx.CodeInternal)))
+//	defer r.Shutdown(context.Background()) // flushes queued errors
+//
+//	r.Report(err)
+package errxsentry
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/report"
+)
+
+// Event is a Sentry error event.
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Exception   ExceptionList     `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+}
+
+// ExceptionList holds the exceptions of an event, oldest (the root cause)
+// first.
+type ExceptionList struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception is one error of a cause chain.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace holds stack frames, outermost caller first.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a Sentry stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// EventOption configures NewEvent.
+type EventOption func(*Event)
+
+// WithEnvironment sets the environment of events, such as "production".
+func WithEnvironment(env string) EventOption {
+	return func(ev *Event) {
+		ev.Environment = env
+	}
+}
+
+// WithRelease sets the release of events, typically the application version.
+func WithRelease(release string) EventOption {
+	return func(ev *Event) {
+		ev.Release = release
+	}
+}
+
+// WithServerName sets the server name of events.
+func WithServerName(name string) EventOption {
+	return func(ev *Event) {
+		ev.ServerName = name
+	}
+}
+
+// WithTag sets a tag on events, in addition to the errx tags.
+func WithTag(key, value string) EventOption {
+	return func(ev *Event) {
+		if ev.Tags == nil {
+			ev.Tags = make(map[string]string)
+		}
+		ev.Tags[key] = value
+	}
+}
+
+// level returns the Sentry level for a severity.
+func level(s errx.Severity) string {
+	switch s {
+	case errx.SeverityInfo:
+		return "info"
+	case errx.SeverityWarning:
+		return "warning"
+	case errx.SeverityCritical:
+		return "fatal"
+	default:
+		return "error"
+	}
+}
+
+// NewEvent converts err into an event. Messages and metadata are redacted with
+// errx.DefaultRedactor. It returns nil for a nil error.
+//
+// The event has one exception per *errx.Error in err's cause chain, plus the
+// root cause if it is not an *errx.Error. The level follows errx.SeverityOf.
+// The outermost *errx.Error provides the logger (its source), tags and extra
+// data: its metadata merged over the metadata of its causes, its details under
+// "details", and its debug message under "debug". The timestamp is the error's
+// creation time.
+func NewEvent(err error, opts ...EventOption) *Event {
+	if err == nil {
+		return nil
+	}
+	t := time.Now()
+	if e, ok := errx.As(err); ok && !e.Time().IsZero() {
+		t = e.Time()
+	}
+	return RecordEvent(report.NewRecord(err, t), opts...)
+}
+
+// RecordEvent converts a record of a report.Reporter into an event, like
+// NewEvent, with the record's time as the timestamp. Records are redacted
+// when they are built, so the event is too.
+func RecordEvent(rec report.Record, opts ...EventOption) *Event {
+	ev := &Event{
+		EventID:     newEventID(),
+		Timestamp:   rec.Time.UTC(),
+		Platform:    "go",
+		Level:       level(rec.Severity),
+		Message:     rec.Message,
+		Exception:   ExceptionList{Values: exceptions(rec.Causes)},
+		Tags:        map[string]string{"code": rec.Code},
+		Fingerprint: []string{rec.Fingerprint},
+	}
+
+	if len(rec.Causes) > 0 && rec.Causes[0].Code != "" {
+		ev.Logger = rec.Source
+		if rec.Reason != "" {
+			ev.Tags["reason"] = rec.Reason
+		}
+		if rec.ID != "" {
+			ev.Tags["error_id"] = rec.ID
+		}
+		for _, tag := range rec.Tags {
+			ev.Tags["tag."+tag] = "true"
+		}
+		ev.Extra = extra(rec.Causes)
+		if len(rec.Details) > 0 {
+			ev.Extra["details"] = rec.Details
+		}
+		ev.Extra["debug"] = rec.Debug
+	}
+
+	for _, opt := range opts {
+		opt(ev)
+	}
+	return ev
+}
+
+// extra merges the metadata of the causes, outer values taking precedence.
+func extra(causes []report.Cause) map[string]any {
+	merged := make(map[string]any)
+	for _, c := range slices.Backward(causes) {
+		maps.Copy(merged, c.Metadata)
+	}
+	return merged
+}
+
+// exceptions returns the exceptions of a cause chain, root cause first.
+func exceptions(causes []report.Cause) []Exception {
+	out := make([]Exception, 0, len(causes))
+	for _, c := range slices.Backward(causes) {
+		if c.Code == "" {
+			out = append(out, Exception{Type: c.Type, Value: c.Message})
+			continue
+		}
+		out = append(out, Exception{
+			Type:       cmp.Or(c.Reason, c.Code),
+			Value:      c.Message,
+			Module:     "errx",
+			Stacktrace: stacktrace(c.Stack),
+		})
+	}
+	return out
+}
+
+// stacktrace symbolizes pcs, outermost caller first as Sentry expects.
+func stacktrace(pcs []uintptr) *Stacktrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var frames []Frame
+	it := runtime.CallersFrames(pcs)
+	for {
+		f, more := it.Next()
+		module, function := splitFunction(f.Function)
+		frames = append(frames, Frame{
+			Function: function,
+			Module:   module,
+			Filename: shortFile(f.File),
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    !isStdlib(module),
+		})
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(frames)
+	return &Stacktrace{Frames: frames}
+}
+
+// splitFunction splits a qualified function name such as
+// "github.com/acme/app/users.(*Repo).Get" into its package path and function.
+func splitFunction(name string) (module, function string) {
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		i := slash + 1 + dot
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// isStdlib reports whether a package path belongs to the standard library,
+// whose paths have no dot in their first element.
+func isStdlib(module string) bool {
+	first, _, _ := strings.Cut(module, "/")
+	return module == "" || !strings.Contains(first, ".")
+}
+
+// shortFile returns the last two elements of a file path.
+func shortFile(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		if j := strings.LastIndexByte(path[:i], '/'); j >= 0 {
+			return path[j+1:]
+		}
+	}
+	return path
+}
+
+// newEventID returns a random 32-character hexadecimal event ID.
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// envelopeHeader is the first line of an envelope.
+type envelopeHeader struct {
+	EventID string    `json:"event_id"`
+	SentAt  time.Time `json:"sent_at"`
+	DSN     string    `json:"dsn,omitempty"`
+}
+
+// itemHeader precedes each envelope item.
+type itemHeader struct {
+	Type   string `json:"type"`
+	Length int    `json:"length"`
+}
+
+// Envelope encodes ev as a Sentry envelope with a single event item. dsn may be
+// empty.
+func Envelope(ev *Event, dsn string, sentAt time.Time) ([]byte, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("encode event: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode terminates each line with '\n'
+	if err := enc.Encode(envelopeHeader{EventID: ev.EventID, SentAt: sentAt.UTC(), DSN: dsn}); err != nil {
+		return nil, fmt.Errorf("encode envelope: %w", err)
+	}
+	if err := enc.Encode(itemHeader{Type: "event", Length: len(payload)}); err != nil {
+		return nil, fmt.Errorf("encode envelope: %w", err)
+	}
+	buf.Write(payload)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,135 @@
+package errxsentry_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxsentry"
+)
+
+type eventSuite struct {
+	suite.Suite
+}
+
+func TestEventSuite(t *testing.T) {
+	suite.Run(t, new(eventSuite))
+}
+
+func chainErr() error {
+	root := errors.New("connection refused")
+	repo := errx.WrapInternal(root, "query failed").
+		WithSource("repository").
+		WithMeta("table", "users").
+		WithMeta("attempt", 1)
+	return errx.Wrap(fmt.Errorf("load: %w", repo), errx.CodeUnavailable, "user service unavailable").
+		WithSource("users").
+		WithReason("DB_DOWN").
+		WithTags("db").
+		WithDetail("user_id", "u-1").
+		WithMeta("attempt", 2).
+		WithMeta("password", "hunter2")
+}
+
+func (s *eventSuite) TestLevelFollowsSeverity() {
+	s.Equal("info", errxsentry.NewEvent(errx.NewNotFound("missing")).Level)
+	s.Equal("warning", errxsentry.NewEvent(errx.NewPermissionDenied("denied")).Level)
+	s.Equal("error", errxsentry.NewEvent(errx.NewInternal("boom")).Level)
+	s.Equal("fatal", errxsentry.NewEvent(errx.NewDataLoss("lost")).Level)
+	s.Equal("warning", errxsentry.NewEvent(errx.NewInternal("expected").WithSeverity(errx.SeverityWarning)).Level)
+}
+
+func (s *eventSuite) TestRedactsTemplateMessage() {
+	err := errx.NewTemplate(errx.CodeInvalidArgument, "reset token {token} is invalid").
+		WithDetail("token", "s3cr3t-tok")
+	ev := errxsentry.NewEvent(err)
+	s.Equal("reset token [REDACTED] is invalid", ev.Message)
+	s.Equal("reset token [REDACTED] is invalid", ev.Exception.Values[0].Value)
+}
+
+func (s *eventSuite) TestNewEvent() {
+	err := chainErr()
+	ev := errxsentry.NewEvent(err, errxsentry.WithEnvironment("production"), errxsentry.WithRelease("1.2.3"), errxsentry.WithTag("region", "eu"))
+
+	s.Len(ev.EventID, 32)
+	s.Equal("go", ev.Platform)
+	s.Equal("error", ev.Level)
+	s.Equal("users", ev.Logger)
+	s.Equal("user service unavailable", ev.Message)
+	s.Equal("production", ev.Environment)
+	s.Equal("1.2.3", ev.Release)
+	s.Equal([]string{errx.Fingerprint(err)}, ev.Fingerprint)
+	s.Equal(map[string]string{"code": "unavailable", "reason": "DB_DOWN", "tag.db": "true", "region": "eu"}, ev.Tags)
+
+	s.Equal("users", ev.Extra["table"])
+	s.Equal(2, ev.Extra["attempt"])
+	s.Equal(errx.RedactedText, ev.Extra["password"])
+	s.Equal(map[string]any{"user_id": "u-1"}, ev.Extra["details"])
+	s.Contains(ev.Extra["debug"], "cause=load: query failed")
+
+	values := ev.Exception.Values
+	s.Require().Len(values, 3)
+	s.Equal("*errors.errorString", values[0].Type)
+	s.Equal("connection refused", values[0].Value)
+	s.Nil(values[0].Stacktrace)
+
+	s.Equal("internal", values[1].Type)
+	s.Equal("query failed", values[1].Value)
+	s.Require().NotNil(values[1].Stacktrace)
+
+	s.Equal("DB_DOWN", values[2].Type)
+	s.Equal("errx", values[2].Module)
+	frames := values[2].Stacktrace.Frames
+	last := frames[len(frames)-1]
+	s.Equal("chainErr", last.Function)
+	s.Equal("github.com/bjaus/errx/errxsentry_test", last.Module)
+	s.Equal("errxsentry/event_test.go", last.Filename)
+	s.True(last.InApp)
+	s.Positive(last.Lineno)
+	s.False(frames[0].InApp, "runtime frames come first")
+}
+
+func (s *eventSuite) TestNewEventPlainError() {
+	ev := errxsentry.NewEvent(errors.New("boom"))
+	s.Equal(map[string]string{"code": "unknown"}, ev.Tags)
+	s.Require().Len(ev.Exception.Values, 1)
+	s.Equal("boom", ev.Exception.Values[0].Value)
+	s.Nil(errxsentry.NewEvent(nil))
+}
+
+func (s *eventSuite) TestNewEventUsesErrorTime() {
+	errx.SetClock(func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) })
+	defer errx.SetClock(nil)
+
+	s.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), errxsentry.NewEvent(errx.NewInternal("boom")).Timestamp)
+}
+
+func (s *eventSuite) TestEnvelope() {
+	ev := errxsentry.NewEvent(errx.NewInternal("boom"))
+	sentAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := errxsentry.Envelope(ev, "https://key@sentry.example.com/42", sentAt)
+	s.Require().NoError(err)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	s.Require().Len(lines, 3)
+	s.JSONEq(fmt.Sprintf(`{"event_id":%q,"sent_at":"2026-01-02T03:04:05Z","dsn":"https://key@sentry.example.com/42"}`, ev.EventID), lines[0])
+	s.JSONEq(fmt.Sprintf(`{"type":"event","length":%d}`, len(lines[2])), lines[1])
+
+	var decoded errxsentry.Event
+	s.Require().NoError(json.Unmarshal([]byte(lines[2]), &decoded))
+	s.Equal(ev.EventID, decoded.EventID)
+	s.True(strings.HasSuffix(string(data), "\n"))
+}
@@ -0,0 +1,142 @@
+package errxsentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bjaus/errx/report"
+	"github.com/bjaus/errx/retry"
+)
+
+// ContentType is the content type of Sentry envelopes.
+const ContentType = "application/x-sentry-envelope"
+
+// clientName identifies this package in the X-Sentry-Auth header.
+const clientName = "errx-sentry/1.0"
+
+// DSN is a parsed Sentry DSN, "https://<public key>@<host>/<project id>".
+type DSN struct {
+	raw       string
+	PublicKey string
+	ProjectID string
+	Endpoint  string // Envelope endpoint, "https://<host>/api/<project id>/envelope/"
+}
+
+// ParseDSN parses a Sentry DSN.
+func ParseDSN(dsn string) (DSN, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return DSN{}, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return DSN{}, fmt.Errorf("invalid DSN: scheme must be http or https")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return DSN{}, fmt.Errorf("invalid DSN: missing public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndexByte(path, '/')
+	projectID := path[i+1:]
+	if projectID == "" {
+		return DSN{}, fmt.Errorf("invalid DSN: missing project ID")
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path[:i+1] + "api/" + projectID + "/envelope/"}
+	return DSN{
+		raw:       dsn,
+		PublicKey: u.User.Username(),
+		ProjectID: projectID,
+		Endpoint:  endpoint.String(),
+	}, nil
+}
+
+// String returns the DSN as given to ParseDSN.
+func (d DSN) String() string {
+	return d.raw
+}
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithHTTPClient sets the client used to send envelopes (default
+// http.DefaultClient).
+func WithHTTPClient(c *http.Client) Option {
+	return func(s *Sink) {
+		s.webhookOpts = append(s.webhookOpts, report.WithHTTPClient(c))
+	}
+}
+
+// WithRetry sets the retry options for sending an envelope. By default an
+// envelope is attempted retry.DefaultMaxAttempts times with exponential
+// backoff, as by report.WebhookSink.
+func WithRetry(opts ...retry.Option) Option {
+	return func(s *Sink) {
+		s.webhookOpts = append(s.webhookOpts, report.WithRetry(opts...))
+	}
+}
+
+// WithEventOptions sets options applied to every event, such as
+// WithEnvironment.
+func WithEventOptions(opts ...EventOption) Option {
+	return func(s *Sink) {
+		s.eventOpts = append(s.eventOpts, opts...)
+	}
+}
+
+// Sink is a report.Sink that sends every record to Sentry as an event built
+// with RecordEvent. Queueing, batching and flushing are left to the
+// report.Reporter it is attached to, and envelopes are delivered, with retries,
+// by a report.WebhookSink.
+type Sink struct {
+	dsn         DSN
+	webhookOpts []report.WebhookOption
+	eventOpts   []EventOption
+	webhook     *report.WebhookSink
+}
+
+// NewSink creates a Sink sending to the project of dsn.
+func NewSink(dsn string, opts ...Option) (*Sink, error) {
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{dsn: d}
+	for _, opt := range opts {
+		opt(s)
+	}
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, d.PublicKey)
+	s.webhook = report.NewWebhookSink(d.Endpoint, append([]report.WebhookOption{
+		report.WithHeader("X-Sentry-Auth", auth),
+		report.WithService("sentry"),
+	}, s.webhookOpts...)...)
+	return s, nil
+}
+
+// Write implements report.Sink. Envelopes carry one event each, so every record
+// is sent as its own request; the errors of the records that could not be sent
+// are joined. Events are built with RecordEvent from the redacted records, not
+// from the reported errors. Records without causes, such as ones built by hand,
+// are skipped.
+func (s *Sink) Write(ctx context.Context, records []report.Record) error {
+	var errs []error
+	for _, rec := range records {
+		if len(rec.Causes) == 0 {
+			continue
+		}
+		errs = append(errs, s.Send(ctx, RecordEvent(rec, s.eventOpts...)))
+	}
+	return errors.Join(errs...)
+}
+
+// Send sends ev as an envelope, retrying failures.
+func (s *Sink) Send(ctx context.Context, ev *Event) error {
+	body, err := Envelope(ev, s.dsn.String(), time.Now())
+	if err != nil {
+		return err
+	}
+	return s.webhook.Post(ctx, ContentType, body)
+}
@@ -0,0 +1,178 @@
+package errxsentry_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxsentry"
+	"github.com/bjaus/errx/report"
+	"github.com/bjaus/errx/retry"
+)
+
+// collector is a Sentry-compatible test server.
+type collector struct {
+	mu       sync.Mutex
+	events   []errxsentry.Event
+	headers  []http.Header
+	paths    []string
+	failures int // number of requests to fail with 503 before accepting
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headers = append(c.headers, r.Header.Clone())
+	c.paths = append(c.paths, r.URL.Path)
+	if c.failures > 0 {
+		c.failures--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	data, _ := io.ReadAll(r.Body)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	var ev errxsentry.Event
+	if len(lines) != 3 || json.Unmarshal([]byte(lines[2]), &ev) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.events = append(c.events, ev)
+	_, _ = io.WriteString(w, `{"id":"`+ev.EventID+`"}`)
+}
+
+func (c *collector) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []string
+	for _, ev := range c.events {
+		out = append(out, ev.Message)
+	}
+	return out
+}
+
+type sinkSuite struct {
+	suite.Suite
+	collector *collector
+	server    *httptest.Server
+	dsn       string
+}
+
+func TestSinkSuite(t *testing.T) {
+	suite.Run(t, new(sinkSuite))
+}
+
+func (s *sinkSuite) SetupTest() {
+	s.collector = &collector{}
+	s.server = httptest.NewServer(s.collector)
+	s.T().Cleanup(s.server.Close)
+	s.dsn = strings.Replace(s.server.URL, "://", "://public@", 1) + "/sentry/42"
+}
+
+func (s *sinkSuite) newSink(opts ...errxsentry.Option) *errxsentry.Sink {
+	opts = append([]errxsentry.Option{
+		errxsentry.WithRetry(retry.WithBaseDelay(time.Millisecond)),
+	}, opts...)
+	sink, err := errxsentry.NewSink(s.dsn, opts...)
+	s.Require().NoError(err)
+	return sink
+}
+
+func (s *sinkSuite) records(errs ...error) []report.Record {
+	var records []report.Record
+	for _, err := range errs {
+		records = append(records, report.NewRecord(err, time.Now()))
+	}
+	return records
+}
+
+func (s *sinkSuite) TestParseDSN() {
+	d, err := errxsentry.ParseDSN("https://abc@sentry.example.com/prefix/42")
+	s.Require().NoError(err)
+	s.Equal("abc", d.PublicKey)
+	s.Equal("42", d.ProjectID)
+	s.Equal("https://sentry.example.com/prefix/api/42/envelope/", d.Endpoint)
+
+	for _, dsn := range []string{"ftp://abc@host/1", "https://host/1", "https://abc@host/", "://"} {
+		_, err := errxsentry.ParseDSN(dsn)
+		s.Error(err, dsn)
+	}
+
+	_, err = errxsentry.NewSink("https://host/1")
+	s.Error(err)
+}
+
+func (s *sinkSuite) TestWrite() {
+	sink := s.newSink(errxsentry.WithEventOptions(errxsentry.WithEnvironment("test")))
+	records := s.records(errx.NewInternal("first"), errx.NewInternal("second"))
+	records = append(records, report.Record{Code: "internal", Message: "by hand"})
+	s.Require().NoError(sink.Write(context.Background(), records))
+	s.Equal([]string{"first", "second"}, s.collector.messages())
+
+	s.Equal("/sentry/api/42/envelope/", s.collector.paths[0])
+	h := s.collector.headers[0]
+	s.Equal(errxsentry.ContentType, h.Get("Content-Type"))
+	s.Contains(h.Get("X-Sentry-Auth"), "sentry_key=public")
+	s.Contains(h.Get("X-Sentry-Auth"), "sentry_version=7")
+	s.Equal("test", s.collector.events[0].Environment)
+}
+
+func (s *sinkSuite) TestWriteUsesRecordSnapshot() {
+	err := errx.NewInternal("boom").WithMeta("attempt", 1).WithMeta("api_key", "k-1")
+	records := s.records(err)
+	err.WithMeta("attempt", 2)
+
+	s.Require().NoError(s.newSink().Write(context.Background(), records))
+	s.Require().Len(s.collector.events, 1)
+	extra := s.collector.events[0].Extra
+	s.InDelta(1, extra["attempt"], 0, "changes after reporting are not sent")
+	s.Equal(errx.RedactedText, extra["api_key"])
+}
+
+func (s *sinkSuite) TestReporter() {
+	r := report.New(report.WithSink(s.newSink(), report.OnlyCodes(errx.CodeInternal)))
+	s.True(r.Report(errx.NewInternal("reported")))
+	s.True(r.Report(errx.NewNotFound("filtered")))
+	s.Require().NoError(r.Shutdown(context.Background()))
+	s.Equal([]string{"reported"}, s.collector.messages())
+}
+
+func (s *sinkSuite) TestRetriesWithBackoff() {
+	s.collector.failures = 2
+	s.Require().NoError(s.newSink().Write(context.Background(), s.records(errx.NewInternal("eventually"))))
+	s.Equal([]string{"eventually"}, s.collector.messages())
+	s.Len(s.collector.paths, 3)
+}
+
+func (s *sinkSuite) TestGivesUpAfterRetries() {
+	s.collector.failures = 10
+	sink := s.newSink(errxsentry.WithRetry(retry.WithMaxAttempts(2), retry.WithBaseDelay(time.Millisecond)))
+	err := sink.Write(context.Background(), s.records(errx.NewInternal("lost")))
+
+	s.Empty(s.collector.messages())
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+	s.Len(s.collector.paths, 2)
+}
+
+func (s *sinkSuite) TestSend() {
+	ev := errxsentry.NewEvent(errx.NewInternal("direct"))
+	s.Require().NoError(s.newSink().Send(context.Background(), ev))
+	s.Equal([]string{"direct"}, s.collector.messages())
+}
@@ -216,8 +216,9 @@ func ExampleError_slogJSON() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelError,
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
-			// Remove timestamp for consistent output
-			if a.Key == "time" {
+			// Remove timestamp and fingerprint for consistent output;
+			// the fingerprint depends on where the error was created
+			if a.Key == "time" || a.Key == "fingerprint" {
 				return slog.Attr{}
 			}
 			return a
@@ -241,7 +242,7 @@ func ExampleError_slogJSON() {
 	logger.Error("operation failed", "error", outerErr)
 
 	// Output:
-	// {"level":"ERROR","msg":"operation failed","error":{"code":"not_found","message":"user not found","source":"user-service","details":{"user_id":"12345"},"metadata":{"request_id":"req-789"},"cause":{"code":"internal","message":"query execution failed","source":"user-repository","tags":["database","postgres"],"metadata":{"query":"SELECT * FROM users"},"debug":"deadlock detected"}}}
+	// {"level":"ERROR","msg":"operation failed","error":{"code":"not_found","message":"user not found","source":"user-service","details":{"user_id":"12345"},"metadata":{"request_id":"req-789"},"cause":{"code":"internal","message":"query execution failed","source":"user-repository","tags":["database","postgres"],"metadata":{"query":"SELECT * FROM users"},"debug":"deadlock detected"},"fault":"client"}}
 }
 
 // ExampleError_slogJSON_threeLevels demonstrates three-level nested error logging.
@@ -249,7 +250,7 @@ func ExampleError_slogJSON_threeLevels() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelError,
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
-			if a.Key == "time" {
+			if a.Key == "time" || a.Key == "fingerprint" {
 				return slog.Attr{}
 			}
 			return a
@@ -276,7 +277,7 @@ func ExampleError_slogJSON_threeLevels() {
 	// Note how each layer is nested in the "cause" field, preserving all context
 
 	// Output:
-	// {"level":"ERROR","msg":"request failed","error":{"code":"not_found","message":"resource not found","source":"service","metadata":{"resource_type":"user"},"cause":{"code":"unavailable","message":"database unavailable","source":"repository","metadata":{"operation":"findByID"},"cause":{"code":"internal","message":"connection refused","source":"postgres-driver","metadata":{"port":5432}}}}}
+	// {"level":"ERROR","msg":"request failed","error":{"code":"not_found","message":"resource not found","source":"service","metadata":{"resource_type":"user"},"cause":{"code":"unavailable","message":"database unavailable","source":"repository","metadata":{"operation":"findByID"},"cause":{"code":"internal","message":"connection refused","source":"postgres-driver","metadata":{"port":5432}}},"fault":"client"}}
 }
 
 // ExampleError_clientVsInternalData demonstrates the separation between client-safe
@@ -0,0 +1,77 @@
+package errx
+
+import (
+	"context"
+	"errors"
+)
+
+// Fault says who is responsible for an error: the caller or the service.
+// The zero value, FaultUnknown, means the error cannot be attributed.
+type Fault uint8
+
+// Faults.
+const (
+	FaultUnknown Fault = iota // Cannot be attributed
+	FaultClient               // The caller did something wrong
+	FaultServer               // The service failed
+)
+
+// String implements the Stringer interface.
+func (f Fault) String() string {
+	switch f {
+	case FaultClient:
+		return "client"
+	case FaultServer:
+		return "server"
+	default:
+		return "unknown"
+	}
+}
+
+// Fault returns who is responsible for errors with the code:
+//
+//	client:  canceled, invalid_argument, not_found, already_exists,
+//	         permission_denied, failed_precondition, out_of_range,
+//	         unauthenticated
+//	server:  deadline_exceeded, unimplemented, internal, unavailable, data_loss
+//	unknown: unknown, resource_exhausted, aborted
+//
+// resource_exhausted and aborted are unknown because they describe a caller
+// exceeding its quota or losing a race as often as the service running out of
+// capacity.
+func (c Code) Fault() Fault {
+	switch c {
+	case CodeCanceled, CodeInvalidArgument, CodeNotFound, CodeAlreadyExists,
+		CodePermissionDenied, CodeFailedPrecondition, CodeOutOfRange, CodeUnauthenticated:
+		return FaultClient
+	case CodeDeadlineExceeded, CodeUnimplemented, CodeInternal, CodeUnavailable, CodeDataLoss:
+		return FaultServer
+	default:
+		return FaultUnknown
+	}
+}
+
+// FaultOf resolves who is responsible for err by walking its whole tree:
+//
+//  1. The nearest *Error whose code is attributed wins, so an unknown error
+//     wrapping not_found is a client fault.
+//  2. Otherwise, a wrapped context.Canceled is a client fault and a wrapped
+//     context.DeadlineExceeded is a server fault.
+//  3. Otherwise, the result is FaultUnknown.
+//
+// SLOs usually count FaultUnknown against the service.
+func FaultOf(err error) Fault {
+	for e := range chain(err) {
+		if f := e.code.Fault(); f != FaultUnknown {
+			return f
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return FaultClient
+	case errors.Is(err, context.DeadlineExceeded):
+		return FaultServer
+	default:
+		return FaultUnknown
+	}
+}
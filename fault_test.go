@@ -0,0 +1,113 @@
+package errx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type faultSuite struct {
+	suite.Suite
+}
+
+func TestFaultSuite(t *testing.T) {
+	suite.Run(t, new(faultSuite))
+}
+
+func (s *faultSuite) TestString() {
+	s.Equal("unknown", errx.FaultUnknown.String())
+	s.Equal("client", errx.FaultClient.String())
+	s.Equal("server", errx.FaultServer.String())
+}
+
+func (s *faultSuite) TestCodeFault() {
+	tests := map[errx.Code]errx.Fault{
+		errx.CodeUnknown:            errx.FaultUnknown,
+		errx.CodeCanceled:           errx.FaultClient,
+		errx.CodeInvalidArgument:    errx.FaultClient,
+		errx.CodeDeadlineExceeded:   errx.FaultServer,
+		errx.CodeNotFound:           errx.FaultClient,
+		errx.CodeAlreadyExists:      errx.FaultClient,
+		errx.CodePermissionDenied:   errx.FaultClient,
+		errx.CodeResourceExhausted:  errx.FaultUnknown,
+		errx.CodeFailedPrecondition: errx.FaultClient,
+		errx.CodeAborted:            errx.FaultUnknown,
+		errx.CodeOutOfRange:         errx.FaultClient,
+		errx.CodeUnimplemented:      errx.FaultServer,
+		errx.CodeInternal:           errx.FaultServer,
+		errx.CodeUnavailable:        errx.FaultServer,
+		errx.CodeDataLoss:           errx.FaultServer,
+		errx.CodeUnauthenticated:    errx.FaultClient,
+	}
+
+	s.Len(tests, len(errx.CodeValues()))
+	for code, want := range tests {
+		s.Run(code.String(), func() {
+			s.Equal(want, code.Fault())
+		})
+	}
+}
+
+func (s *faultSuite) TestFaultOf() {
+	tests := map[string]struct {
+		err  error
+		want errx.Fault
+	}{
+		"nil":      {err: nil, want: errx.FaultUnknown},
+		"plain":    {err: errors.New("boom"), want: errx.FaultUnknown},
+		"client":   {err: errx.NewNotFound("missing"), want: errx.FaultClient},
+		"server":   {err: errx.NewInternal("boom"), want: errx.FaultServer},
+		"fmt wrap": {err: fmt.Errorf("load: %w", errx.NewInvalidArgument("bad")), want: errx.FaultClient},
+		"outer code wins": {
+			err:  errx.WrapInternal(errx.NewNotFound("missing"), "lookup failed"),
+			want: errx.FaultServer,
+		},
+		"unknown wraps client": {
+			err:  errx.Wrap(errx.NewNotFound("missing"), errx.CodeUnknown, "lookup failed"),
+			want: errx.FaultClient,
+		},
+		"unattributed chain": {
+			err:  errx.Wrap(errx.NewAborted("conflict"), errx.CodeUnknown, "save failed"),
+			want: errx.FaultUnknown,
+		},
+		"joined": {
+			err:  errors.Join(errors.New("boom"), errx.NewUnavailable("down")),
+			want: errx.FaultServer,
+		},
+		"context canceled": {
+			err:  errx.Wrap(context.Canceled, errx.CodeUnknown, "query failed"),
+			want: errx.FaultClient,
+		},
+		"context deadline": {
+			err:  fmt.Errorf("query: %w", context.DeadlineExceeded),
+			want: errx.FaultServer,
+		},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Equal(tt.want, errx.FaultOf(tt.err))
+		})
+	}
+}
+
+func (s *faultSuite) TestLogValue() {
+	var buf bytes.Buffer
+	err := errx.Wrap(errx.NewNotFound("missing"), errx.CodeUnknown, "lookup failed")
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("x", "error", err)
+
+	var entry struct {
+		Error map[string]any `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("client", entry.Error["fault"])
+	s.NotContains(entry.Error["cause"], "fault")
+}
@@ -0,0 +1,137 @@
+package errx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultFingerprintFrames is the number of stack frames hashed by Fingerprint.
+const DefaultFingerprintFrames = 5
+
+// FingerprintOption configures Fingerprint.
+type FingerprintOption func(*fingerprintConfig)
+
+type fingerprintConfig struct {
+	frames      int
+	ignoreLines bool
+	filter      func(runtime.Frame) bool
+}
+
+// WithFingerprintFrames sets how many stack frames are hashed (default
+// [DefaultFingerprintFrames]). Zero hashes no frames, grouping errors by code,
+// reason, source and message only.
+func WithFingerprintFrames(n int) FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.frames = max(n, 0)
+	}
+}
+
+// WithoutLineNumbers hashes frames by function name only, so fingerprints
+// survive edits that move code within a function.
+func WithoutLineNumbers() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.ignoreLines = true
+	}
+}
+
+// WithFrameFilter sets the filter selecting the frames to hash, replacing the
+// default, which skips runtime and testing frames and frames of this package.
+func WithFrameFilter(keep func(runtime.Frame) bool) FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.filter = keep
+	}
+}
+
+// defaultFrameFilter skips frames that do not identify the failing code path.
+func defaultFrameFilter(f runtime.Frame) bool {
+	for _, prefix := range []string{"runtime.", "testing.", "github.com/bjaus/errx."} {
+		if strings.HasPrefix(f.Function, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint returns a stable hash identifying the kind of failure behind err,
+// for grouping identical failures in alerting and log backends. It is computed
+// from the originating *Error in err's chain (the innermost one): its code,
+// reason, source, message template and the top stack frames where it was
+// created. Templates are hashed unrendered, so errors created with NewTemplate
+// group together whatever their details:
+//
+//	errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found") // same fingerprint for every user
+//
+// Other messages often interpolate IDs, as with Newf, so they are left out when
+// stack frames are hashed: the call site identifies the failure instead. They
+// are hashed only when no frames are, such as with WithFingerprintFrames(0).
+// Errors without an *Error in their chain are fingerprinted by type and message.
+// Fingerprint returns "" for nil.
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+	cfg := fingerprintConfig{frames: DefaultFingerprintFrames, filter: defaultFrameFilter}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	e, ok := As(err)
+	if !ok {
+		write(fmt.Sprintf("%T", err))
+		write(err.Error())
+		return hex.EncodeToString(h.Sum(nil)[:8])
+	}
+	for {
+		cause, ok := As(e.cause)
+		if !ok {
+			break
+		}
+		e = cause
+	}
+
+	frames := cfg.stackFrames(e.stackTrace)
+	write(e.code.String())
+	write(e.reason)
+	write(e.source)
+	if e.templated || len(frames) == 0 {
+		write(e.message)
+	}
+	for _, f := range frames {
+		write(f)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// stackFrames returns the frames of stack to hash, as "function:line", or as
+// "function" when line numbers are ignored.
+func (c *fingerprintConfig) stackFrames(stack []uintptr) []string {
+	if c.frames == 0 || len(stack) == 0 {
+		return nil
+	}
+	var out []string
+	frames := runtime.CallersFrames(stack)
+	for len(out) < c.frames {
+		frame, more := frames.Next()
+		if c.filter == nil || c.filter(frame) {
+			if c.ignoreLines {
+				out = append(out, frame.Function)
+			} else {
+				out = append(out, frame.Function+":"+strconv.Itoa(frame.Line))
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
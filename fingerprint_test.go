@@ -0,0 +1,102 @@
+package errx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type fingerprintSuite struct {
+	suite.Suite
+}
+
+func TestFingerprintSuite(t *testing.T) {
+	suite.Run(t, new(fingerprintSuite))
+}
+
+// userNotFound creates the same failure from one place for every id.
+func userNotFound(id string) *errx.Error {
+	return errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").WithDetail("user_id", id)
+}
+
+func (s *fingerprintSuite) TestStableAcrossDetails() {
+	var fingerprints []string
+	for _, id := range []string{"u-1", "u-2"} {
+		fingerprints = append(fingerprints, errx.Fingerprint(userNotFound(id)))
+	}
+	s.Equal(fingerprints[0], fingerprints[1])
+	s.Len(fingerprints[0], 16)
+}
+
+// userMissing creates the same failure from one place, interpolating the id.
+func userMissing(id string) *errx.Error {
+	return errx.Newf(errx.CodeNotFound, "user %s not found", id)
+}
+
+func (s *fingerprintSuite) TestStableAcrossInterpolatedMessages() {
+	a, b := userMissing("u-1"), userMissing("u-2")
+	s.Equal(errx.Fingerprint(a), errx.Fingerprint(b))
+	s.Equal(errx.Fingerprint(a, errx.WithoutLineNumbers()), errx.Fingerprint(b, errx.WithoutLineNumbers()))
+	s.NotEqual(errx.Fingerprint(a, errx.WithFingerprintFrames(0)), errx.Fingerprint(b, errx.WithFingerprintFrames(0)),
+		"messages are hashed when no frames are")
+}
+
+func (s *fingerprintSuite) TestDiffersByIdentity() {
+	opt := errx.WithFingerprintFrames(0)
+	base := errx.Fingerprint(userNotFound("u-1"), opt)
+	s.NotEqual(base, errx.Fingerprint(userNotFound("u-1").WithReason("USER_DELETED"), opt))
+	s.NotEqual(base, errx.Fingerprint(userNotFound("u-1").WithSource("users"), opt))
+	s.NotEqual(base, errx.Fingerprint(errx.NewNotFound("user u-1 not found"), opt))
+}
+
+func (s *fingerprintSuite) TestDiffersByCallSite() {
+	a := errx.NewInternal("boom")
+	b := errx.NewInternal("boom")
+	s.NotEqual(errx.Fingerprint(a), errx.Fingerprint(b))
+	s.Equal(errx.Fingerprint(a, errx.WithoutLineNumbers()), errx.Fingerprint(b, errx.WithoutLineNumbers()))
+	s.Equal(errx.Fingerprint(a, errx.WithFingerprintFrames(0)), errx.Fingerprint(b, errx.WithFingerprintFrames(0)))
+}
+
+func (s *fingerprintSuite) TestUsesOriginatingError() {
+	origin := userNotFound("u-1")
+	wrapped := errx.Wrap(fmt.Errorf("lookup: %w", origin), errx.CodeUnavailable, "try later").WithSource("api")
+	s.Equal(errx.Fingerprint(origin), errx.Fingerprint(wrapped))
+}
+
+func (s *fingerprintSuite) TestFrameFilter() {
+	var seen []string
+	errx.Fingerprint(userNotFound("u-1"), errx.WithFrameFilter(func(f runtime.Frame) bool {
+		seen = append(seen, f.Function)
+		return true
+	}))
+	s.Require().NotEmpty(seen)
+	s.True(strings.HasSuffix(seen[0], "userNotFound"), seen[0])
+}
+
+func (s *fingerprintSuite) TestPlainErrors() {
+	s.Empty(errx.Fingerprint(nil))
+	s.Equal(errx.Fingerprint(errors.New("boom")), errx.Fingerprint(errors.New("boom")))
+	s.NotEqual(errx.Fingerprint(errors.New("boom")), errx.Fingerprint(errors.New("bang")))
+}
+
+func (s *fingerprintSuite) TestLogValue() {
+	err := errx.Wrap(userNotFound("u-1"), errx.CodeInternal, "lookup failed")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", err)
+	var entry struct {
+		Error map[string]any `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal(errx.Fingerprint(err), entry.Error["fingerprint"])
+	s.NotContains(entry.Error["cause"], "fingerprint")
+}
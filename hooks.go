@@ -0,0 +1,140 @@
+package errx
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// hook is a registered hook. Hooks are compared by pointer, so the same
+// function can be registered twice and unregistered independently.
+type hook[F any] struct {
+	fn F
+}
+
+// hookSet is an immutable set of hooks, replaced as a whole on registration so
+// newError can read it with a single atomic load.
+type hookSet struct {
+	create []*hook[func(*Error)]
+	wrap   []*hook[func(*Error, error)]
+	check  []*hook[func(*Error)]
+}
+
+var hooks atomic.Pointer[hookSet]
+
+// OnCreate registers fn to be called with every new *Error, including wrapping
+// errors, and returns a function that unregisters it. Use it to count or sample
+// errors as they are created:
+//
+//	unregister := errx.OnCreate(func(e *errx.Error) { created.Add(1) })
+//	t.Cleanup(unregister)
+//
+// Hooks run synchronously in the goroutine creating the error, after the code,
+// message, cause, stack trace, instance ID and creation time are set but before
+// methods such as WithSource or WithMeta are applied. Use OnCheck for policies
+// on those fields. Hooks must be fast, safe for concurrent use, and must not
+// keep e for reading later, since the creator may still modify it. Register
+// hooks during initialization: registering copies the hook list, while
+// creating an error only loads it.
+func OnCreate(fn func(e *Error)) (unregister func()) {
+	h := &hook[func(*Error)]{fn: fn}
+	updateHooks(func(s *hookSet) { s.create = append(s.create, h) })
+	return func() {
+		updateHooks(func(s *hookSet) {
+			s.create = slices.DeleteFunc(s.create, func(x *hook[func(*Error)]) bool { return x == h })
+		})
+	}
+}
+
+// OnWrap registers fn to be called with every new *Error that wraps a cause, and
+// returns a function that unregisters it. Wrap hooks run after the create hooks,
+// under the same rules as OnCreate.
+func OnWrap(fn func(e *Error, cause error)) (unregister func()) {
+	h := &hook[func(*Error, error)]{fn: fn}
+	updateHooks(func(s *hookSet) { s.wrap = append(s.wrap, h) })
+	return func() {
+		updateHooks(func(s *hookSet) {
+			s.wrap = slices.DeleteFunc(s.wrap, func(x *hook[func(*Error, error)]) bool { return x == h })
+		})
+	}
+}
+
+// OnCheck registers fn to be called with finished errors where they are
+// handled, and returns a function that unregisters it. Check hooks run when an
+// error is logged with Log, written to a client by errxhttp.WriteError, or
+// passed to Check, so they see every field set with methods such as
+// WithSource or WithMeta. Use them to enforce policies in tests:
+//
+//	t.Cleanup(errx.OnCheck(func(e *errx.Error) {
+//	    if e.Source() == "" {
+//	        t.Errorf("error without a source: %s", e)
+//	    }
+//	}))
+//
+// fn receives the outermost *Error of the handled error; walk Unwrap to check
+// its causes. An error handled in several places is checked each time. Check
+// hooks follow the same rules as OnCreate.
+func OnCheck(fn func(e *Error)) (unregister func()) {
+	h := &hook[func(*Error)]{fn: fn}
+	updateHooks(func(s *hookSet) { s.check = append(s.check, h) })
+	return func() {
+		updateHooks(func(s *hookSet) {
+			s.check = slices.DeleteFunc(s.check, func(x *hook[func(*Error)]) bool { return x == h })
+		})
+	}
+}
+
+// Check calls the hooks registered with OnCheck with the outermost *Error in
+// err. Packages that hand errors to clients or reporters call it where an error
+// is handled; it does nothing if err is not or does not wrap an *Error.
+func Check(err error) {
+	s := hooks.Load()
+	if s == nil || len(s.check) == 0 {
+		return
+	}
+	e, ok := As(err)
+	if !ok {
+		return
+	}
+	for _, h := range s.check {
+		h.fn(e)
+	}
+}
+
+// updateHooks replaces the hook set with a copy modified by update.
+func updateHooks(update func(*hookSet)) {
+	for {
+		old := hooks.Load()
+		var next hookSet
+		if old != nil {
+			next.create = slices.Clone(old.create)
+			next.wrap = slices.Clone(old.wrap)
+			next.check = slices.Clone(old.check)
+		}
+		update(&next)
+		if len(next.create) == 0 && len(next.wrap) == 0 && len(next.check) == 0 {
+			if hooks.CompareAndSwap(old, nil) {
+				return
+			}
+			continue
+		}
+		if hooks.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// runHooks calls the registered hooks for a new error.
+func runHooks(e *Error) {
+	s := hooks.Load()
+	if s == nil {
+		return
+	}
+	for _, h := range s.create {
+		h.fn(e)
+	}
+	if e.cause != nil {
+		for _, h := range s.wrap {
+			h.fn(e, e.cause)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package errx_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type hooksSuite struct {
+	suite.Suite
+}
+
+func TestHooksSuite(t *testing.T) {
+	suite.Run(t, new(hooksSuite))
+}
+
+func (s *hooksSuite) TestOnCreate() {
+	var created []*errx.Error
+	unregister := errx.OnCreate(func(e *errx.Error) { created = append(created, e) })
+
+	inner := errx.NewNotFound("user not found")
+	outer := errx.Wrap(inner, errx.CodeInternal, "lookup failed")
+	errx.Ensure(outer, errx.CodeInternal, "unexpected") // returns outer, creates nothing
+	s.Equal([]*errx.Error{inner, outer}, created)
+	s.NotEmpty(created[0].StackTrace())
+
+	unregister()
+	errx.NewInternal("boom")
+	s.Len(created, 2)
+}
+
+func (s *hooksSuite) TestOnWrap() {
+	var causes []error
+	defer errx.OnWrap(func(_ *errx.Error, cause error) { causes = append(causes, cause) })()
+
+	base := errors.New("connection refused")
+	errx.NewInternal("boom")
+	errx.Wrap(base, errx.CodeUnavailable, "db down")
+	errx.Wrap(nil, errx.CodeUnavailable, "db down")
+	s.Equal([]error{base}, causes)
+}
+
+func (s *hooksSuite) TestOrderAndUnregisterOne() {
+	var calls []string
+	unregisterA := errx.OnCreate(func(*errx.Error) { calls = append(calls, "a") })
+	unregisterB := errx.OnCreate(func(*errx.Error) { calls = append(calls, "b") })
+	defer errx.OnWrap(func(*errx.Error, error) { calls = append(calls, "wrap") })()
+	defer unregisterB()
+
+	errx.Wrap(errors.New("x"), errx.CodeInternal, "boom")
+	s.Equal([]string{"a", "b", "wrap"}, calls)
+
+	unregisterA()
+	unregisterA() // unregistering twice is harmless
+	calls = nil
+	errx.NewInternal("boom")
+	s.Equal([]string{"b"}, calls)
+}
+
+func (s *hooksSuite) TestConcurrentRegistration() {
+	var n atomic.Int64
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				unregister := errx.OnCreate(func(*errx.Error) { n.Add(1) })
+				errx.NewInternal("boom")
+				unregister()
+			}
+		}()
+	}
+	wg.Wait()
+	s.Positive(n.Load())
+
+	before := n.Load()
+	errx.NewInternal("boom")
+	s.Equal(before, n.Load(), "all hooks unregistered")
+}
+
+func (s *hooksSuite) TestOnCheck() {
+	var missing []string
+	unregister := errx.OnCheck(func(e *errx.Error) {
+		if e.Source() == "" {
+			missing = append(missing, e.Error())
+		}
+	})
+
+	errx.NewInternal("boom") // created but never handled
+	errx.Check(errx.NewNotFound("user not found").WithSource("users"))
+	errx.Check(errx.Wrap(errx.NewNotFound("inner"), errx.CodeInternal, "lookup failed"))
+	errx.Check(errors.New("plain"))
+	errx.Check(nil)
+	errx.Log(context.Background(), slog.New(slog.DiscardHandler), "request failed", errx.NewUnavailable("db down"))
+	s.Equal([]string{"lookup failed", "db down"}, missing)
+
+	unregister()
+	errx.Check(errx.NewInternal("boom"))
+	s.Len(missing, 2)
+}
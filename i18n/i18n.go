@@ -0,0 +1,272 @@
+// Package i18n provides an errx.Localizer backed by per-locale message catalogs.
+//
+// A catalog is a JSON object mapping message keys to messages. A message is
+// either a string or an object of CLDR plural forms, selected by the numeric
+// "count" parameter with the language's plural rule:
+//
+//	{
+//	  "invite.expired": "Your invite {invite_id} has expired.",
+//	  "invite.quota": {
+//	    "one": "You can send {count} invite per day.",
+//	    "other": "You can send {count} invites per day."
+//	  }
+//	}
+//
+// Placeholders such as {invite_id} are replaced with the parameter of that name;
+// placeholders without a parameter are left as is. Catalogs are loaded per
+// language, typically from embedded files named after their BCP 47 tag:
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	bundle := i18n.NewBundle()
+//	if err := bundle.LoadFS(locales, "locales"); err != nil { ... }
+//	errx.SetLocalizer(bundle)
+//
+//	err := errx.NewFailedPrecondition("invite expired").
+//	    WithMessageKey("invite.expired", map[string]any{"invite_id": id})
+//	err.LocalizedMessage("de-AT") // tries "de-AT", then "de"
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bjaus/errx"
+)
+
+var _ errx.Localizer = (*Bundle)(nil)
+
+// CountParam is the parameter that selects the plural form of a message.
+const CountParam = "count"
+
+// Message is a translated message: a single text, or texts per plural form.
+type Message struct {
+	Text  string          // Used when the message has no plural forms
+	Forms map[Form]string // Plural forms; Other is required
+}
+
+// UnmarshalJSON accepts either a string or an object of plural forms.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = Message{Text: text}
+		return nil
+	}
+	var forms map[Form]string
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return fmt.Errorf("message must be a string or an object of plural forms")
+	}
+	for form := range forms {
+		if !form.IsValid() {
+			return fmt.Errorf("unknown plural form %q", form)
+		}
+	}
+	if _, ok := forms[Other]; !ok {
+		return fmt.Errorf("plural message has no %q form", Other)
+	}
+	*m = Message{Forms: forms}
+	return nil
+}
+
+// Bundle holds message catalogs for several languages. It implements
+// errx.Localizer and is safe for concurrent use.
+type Bundle struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]Message // by normalized language, then key
+	rules    map[string]PluralRule         // by normalized language
+}
+
+// NewBundle creates an empty Bundle.
+func NewBundle() *Bundle {
+	return &Bundle{
+		messages: make(map[string]map[string]Message),
+		rules:    make(map[string]PluralRule),
+	}
+}
+
+// AddMessages adds messages for lang, replacing existing messages with the same key.
+func (b *Bundle) AddMessages(lang string, messages map[string]Message) {
+	lang = normalize(lang)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog, ok := b.messages[lang]
+	if !ok {
+		catalog = make(map[string]Message, len(messages))
+		b.messages[lang] = catalog
+	}
+	for key, m := range messages {
+		catalog[key] = m
+	}
+}
+
+// LoadJSON adds the messages of a JSON catalog for lang.
+func (b *Bundle) LoadJSON(lang string, data []byte) error {
+	var messages map[string]Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("load %s catalog: %w", lang, err)
+	}
+	b.AddMessages(lang, messages)
+	return nil
+}
+
+// LoadFile adds the messages of a JSON catalog file for lang.
+func (b *Bundle) LoadFile(lang, name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("load %s catalog: %w", lang, err)
+	}
+	return b.LoadJSON(lang, data)
+}
+
+// LoadFS adds every "<lang>.json" catalog in dir of fsys, such as an embed.FS.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("load catalogs: %w", err)
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("load catalogs: %w", err)
+		}
+		lang := strings.TrimSuffix(path.Base(name), ".json")
+		if err := b.LoadJSON(lang, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetPluralRule overrides the plural rule used for lang.
+func (b *Bundle) SetPluralRule(lang string, rule PluralRule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rules[normalize(lang)] = rule
+}
+
+// Languages returns the languages that have messages, in no particular order.
+func (b *Bundle) Languages() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	langs := make([]string, 0, len(b.messages))
+	for lang := range b.messages {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+// Localize implements errx.Localizer. It looks key up for lang and then for
+// lang's base language ("pt" for "pt-BR"), without falling back to other
+// languages, so callers can try languages in order of preference.
+func (b *Bundle) Localize(lang, key string, params map[string]any) (string, bool) {
+	lang = normalize(lang)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, tag := range candidates(lang) {
+		m, ok := b.messages[tag][key]
+		if !ok {
+			continue
+		}
+		text := m.Text
+		if m.Forms != nil {
+			text = m.Forms[b.form(tag, params)]
+			if text == "" {
+				text = m.Forms[Other]
+			}
+		}
+		return Interpolate(text, params), true
+	}
+	return "", false
+}
+
+// form returns the plural form selected by the count parameter for lang.
+// The caller must hold b.mu.
+func (b *Bundle) form(lang string, params map[string]any) Form {
+	n, ok := number(params[CountParam])
+	if !ok {
+		return Other
+	}
+	rule, ok := b.rules[lang]
+	if !ok {
+		rule, ok = b.rules[base(lang)]
+	}
+	if !ok {
+		rule = RuleFor(lang)
+	}
+	return rule(n)
+}
+
+// Interpolate replaces {name} placeholders in text with the matching params,
+// formatted with fmt.Sprint. Placeholders without a parameter are left as is.
+// It is errx.Interpolate, so catalogs use the same syntax as errx templates.
+func Interpolate(text string, params map[string]any) string {
+	return errx.Interpolate(text, params)
+}
+
+// normalize lower-cases a language tag and uses "-" as the subtag separator.
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// base returns the primary language subtag of a normalized tag.
+func base(lang string) string {
+	if i := strings.IndexByte(lang, '-'); i >= 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// candidates returns the tags to try for a normalized tag, most specific first.
+func candidates(lang string) []string {
+	if b := base(lang); b != lang {
+		return []string{lang, b}
+	}
+	return []string{lang}
+}
+
+// number converts a numeric parameter to float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,123 @@
+package i18n_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/i18n"
+)
+
+type bundleSuite struct {
+	suite.Suite
+	bundle *i18n.Bundle
+}
+
+func TestBundleSuite(t *testing.T) {
+	suite.Run(t, new(bundleSuite))
+}
+
+func (s *bundleSuite) SetupTest() {
+	s.bundle = i18n.NewBundle()
+	s.Require().NoError(s.bundle.LoadFS(os.DirFS("testdata"), "locales"))
+}
+
+func (s *bundleSuite) TearDownTest() {
+	errx.SetLocalizer(nil)
+}
+
+func (s *bundleSuite) TestLoadFS() {
+	s.ElementsMatch([]string{"en", "ru", "pt-br"}, s.bundle.Languages())
+}
+
+func (s *bundleSuite) TestLocalize() {
+	msg, ok := s.bundle.Localize("en", "invite.expired", map[string]any{"invite_id": "inv-1"})
+	s.True(ok)
+	s.Equal("Your invite inv-1 has expired.", msg)
+
+	msg, ok = s.bundle.Localize("en-GB", "invite.expired", nil)
+	s.True(ok, "regional tags fall back to the base language")
+	s.Equal("Your invite {invite_id} has expired.", msg, "placeholders without params are kept")
+
+	msg, ok = s.bundle.Localize("pt_BR", "invite.expired", map[string]any{"invite_id": "inv-1"})
+	s.True(ok, "tags are normalized")
+	s.Equal("Seu convite inv-1 expirou.", msg)
+
+	_, ok = s.bundle.Localize("pt", "invite.expired", nil)
+	s.False(ok, "base languages do not fall back to regional catalogs")
+
+	_, ok = s.bundle.Localize("ru", "invite.expired", nil)
+	s.False(ok, "missing keys do not fall back to other languages")
+}
+
+func (s *bundleSuite) TestPlurals() {
+	tests := []struct {
+		lang  string
+		count any
+		want  string
+	}{
+		{"en", 1, "You can send 1 invite per day."},
+		{"en", 5, "You can send 5 invites per day."},
+		{"en", "1", "You can send 1 invite per day."},
+		{"en", nil, "You can send {count} invites per day."},
+		{"ru", 1, "Можно отправить 1 приглашение в день."},
+		{"ru", int64(3), "Можно отправить 3 приглашения в день."},
+		{"ru", uint(11), "Можно отправить 11 приглашений в день."},
+		{"ru", 1.5, "Можно отправить 1.5 приглашения в день."},
+	}
+	for _, tt := range tests {
+		params := map[string]any{}
+		if tt.count != nil {
+			params[i18n.CountParam] = tt.count
+		}
+		msg, ok := s.bundle.Localize(tt.lang, "invite.quota", params)
+		s.True(ok)
+		s.Equal(tt.want, msg, "%s %v", tt.lang, tt.count)
+	}
+}
+
+func (s *bundleSuite) TestSetPluralRule() {
+	s.bundle.SetPluralRule("en", func(float64) i18n.Form { return i18n.One })
+	msg, _ := s.bundle.Localize("en-US", "invite.quota", map[string]any{"count": 9})
+	s.Equal("You can send 9 invite per day.", msg)
+}
+
+func (s *bundleSuite) TestMissingFormUsesOther() {
+	s.bundle.AddMessages("ar", map[string]i18n.Message{
+		"n": {Forms: map[i18n.Form]string{i18n.Other: "{count} other"}},
+	})
+	msg, _ := s.bundle.Localize("ar", "n", map[string]any{"count": 0})
+	s.Equal("0 other", msg)
+}
+
+func (s *bundleSuite) TestLoadJSONErrors() {
+	s.ErrorContains(s.bundle.LoadJSON("en", []byte(`{"k": 1}`)), "must be a string or an object of plural forms")
+	s.ErrorContains(s.bundle.LoadJSON("en", []byte(`{"k": {"several": "x", "other": "y"}}`)), `unknown plural form "several"`)
+	s.ErrorContains(s.bundle.LoadJSON("en", []byte(`{"k": {"one": "x"}}`)), `no "other" form`)
+}
+
+func (s *bundleSuite) TestLoadFile() {
+	s.Require().NoError(s.bundle.LoadFile("es", filepath.Join("testdata", "locales", "pt-BR.json")))
+	_, ok := s.bundle.Localize("es", "invite.expired", nil)
+	s.True(ok)
+
+	s.ErrorIs(s.bundle.LoadFile("es", filepath.Join("testdata", "missing.json")), os.ErrNotExist)
+}
+
+func (s *bundleSuite) TestAsErrxLocalizer() {
+	errx.SetLocalizer(s.bundle)
+	err := errx.NewResourceExhausted("invite quota exceeded").
+		WithMessageKey("invite.quota", nil).
+		WithDetail("count", 2)
+
+	s.Equal("You can send 2 invites per day.", err.LocalizedMessage("en-US"))
+	s.Equal("invite quota exceeded", err.LocalizedMessage("ja"))
+}
+
+func (s *bundleSuite) TestInterpolate() {
+	s.Equal("a 1 b {c}", i18n.Interpolate("a {a} b {c}", map[string]any{"a": 1}))
+	s.Equal("{a}", i18n.Interpolate("{a}", nil))
+}
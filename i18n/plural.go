@@ -0,0 +1,147 @@
+package i18n
+
+import "math"
+
+// Form is a CLDR plural category.
+type Form string
+
+// CLDR plural categories.
+const (
+	Zero  Form = "zero"
+	One   Form = "one"
+	Two   Form = "two"
+	Few   Form = "few"
+	Many  Form = "many"
+	Other Form = "other"
+)
+
+// IsValid reports whether f is a CLDR plural category.
+func (f Form) IsValid() bool {
+	switch f {
+	case Zero, One, Two, Few, Many, Other:
+		return true
+	default:
+		return false
+	}
+}
+
+// PluralRule selects the plural form for a count.
+type PluralRule func(n float64) Form
+
+// rules maps base languages to their built-in plural rules. Languages not listed
+// use the English rule.
+var rules = map[string]PluralRule{
+	// one for 1, other otherwise
+	"en": oneRule, "de": oneRule, "nl": oneRule, "sv": oneRule, "da": oneRule,
+	"nb": oneRule, "nn": oneRule, "no": oneRule, "fi": oneRule, "et": oneRule,
+	"it": oneRule, "es": oneRule, "el": oneRule, "hu": oneRule, "tr": oneRule,
+	"bg": oneRule, "ca": oneRule,
+
+	// one for 0 and 1
+	"fr": zeroOneRule, "pt": zeroOneRule,
+
+	// no plural forms
+	"ja": otherRule, "zh": otherRule, "ko": otherRule, "th": otherRule,
+	"vi": otherRule, "id": otherRule, "ms": otherRule,
+
+	"ru": eastSlavicRule, "uk": eastSlavicRule, "be": eastSlavicRule,
+	"pl": polishRule,
+	"cs": czechRule, "sk": czechRule,
+	"ar": arabicRule,
+}
+
+// RuleFor returns the built-in plural rule for a language tag, based on its
+// primary language. Languages without a built-in rule use the English rule.
+func RuleFor(lang string) PluralRule {
+	if rule, ok := rules[base(normalize(lang))]; ok {
+		return rule
+	}
+	return oneRule
+}
+
+// operands returns the absolute integer part of n and whether n has a fraction.
+func operands(n float64) (int64, bool) {
+	n = math.Abs(n)
+	i := math.Trunc(n)
+	return int64(i), n != i
+}
+
+func otherRule(float64) Form {
+	return Other
+}
+
+func oneRule(n float64) Form {
+	if i, frac := operands(n); i == 1 && !frac {
+		return One
+	}
+	return Other
+}
+
+func zeroOneRule(n float64) Form {
+	if i, _ := operands(n); i <= 1 {
+		return One
+	}
+	return Other
+}
+
+func eastSlavicRule(n float64) Form {
+	i, frac := operands(n)
+	switch {
+	case frac:
+		return Other
+	case i%10 == 1 && i%100 != 11:
+		return One
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func polishRule(n float64) Form {
+	i, frac := operands(n)
+	switch {
+	case frac:
+		return Other
+	case i == 1:
+		return One
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+func czechRule(n float64) Form {
+	i, frac := operands(n)
+	switch {
+	case frac:
+		return Many
+	case i == 1:
+		return One
+	case i >= 2 && i <= 4:
+		return Few
+	default:
+		return Other
+	}
+}
+
+func arabicRule(n float64) Form {
+	i, frac := operands(n)
+	switch {
+	case frac:
+		return Other
+	case i == 0:
+		return Zero
+	case i == 1:
+		return One
+	case i == 2:
+		return Two
+	case i%100 >= 3 && i%100 <= 10:
+		return Few
+	case i%100 >= 11:
+		return Many
+	default:
+		return Other
+	}
+}
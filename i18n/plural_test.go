@@ -0,0 +1,42 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx/i18n"
+)
+
+type pluralSuite struct {
+	suite.Suite
+}
+
+func TestPluralSuite(t *testing.T) {
+	suite.Run(t, new(pluralSuite))
+}
+
+func (s *pluralSuite) TestRules() {
+	tests := map[string]map[float64]i18n.Form{
+		"en":    {0: i18n.Other, 1: i18n.One, 1.5: i18n.Other, 2: i18n.Other},
+		"en-US": {1: i18n.One, 3: i18n.Other},
+		"fr":    {0: i18n.One, 1.5: i18n.One, 2: i18n.Other},
+		"ja":    {1: i18n.Other},
+		"ru":    {1: i18n.One, 21: i18n.One, 11: i18n.Many, 2: i18n.Few, 22: i18n.Few, 12: i18n.Many, 5: i18n.Many, 0.5: i18n.Other},
+		"pl":    {1: i18n.One, 21: i18n.Many, 3: i18n.Few, 13: i18n.Many},
+		"cs":    {1: i18n.One, 4: i18n.Few, 5: i18n.Other, 1.5: i18n.Many},
+		"ar":    {0: i18n.Zero, 1: i18n.One, 2: i18n.Two, 5: i18n.Few, 11: i18n.Many, 100: i18n.Other},
+		"xx":    {1: i18n.One, 2: i18n.Other},
+	}
+	for lang, cases := range tests {
+		rule := i18n.RuleFor(lang)
+		for n, want := range cases {
+			s.Equal(want, rule(n), "%s %v", lang, n)
+		}
+	}
+}
+
+func (s *pluralSuite) TestFormIsValid() {
+	s.True(i18n.Few.IsValid())
+	s.False(i18n.Form("several").IsValid())
+}
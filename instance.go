@@ -0,0 +1,139 @@
+package errx
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator returns a unique instance ID for an error created at t.
+type IDGenerator func(t time.Time) string
+
+// instanceConfig holds the clock and ID generator used by newError.
+type instanceConfig struct {
+	now   func() time.Time
+	newID IDGenerator // nil disables instance IDs
+}
+
+var instance atomic.Pointer[instanceConfig]
+
+func init() {
+	instance.Store(&instanceConfig{now: time.Now})
+}
+
+// SetClock sets the time source for the creation time of new errors, for
+// deterministic tests. Passing nil restores time.Now.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	for {
+		old := instance.Load()
+		if instance.CompareAndSwap(old, &instanceConfig{now: now, newID: old.newID}) {
+			return
+		}
+	}
+}
+
+// SetIDGenerator sets the generator of instance IDs for new errors. Instance IDs
+// are disabled by default; enable them at startup with
+//
+//	errx.SetIDGenerator(errx.NewID)
+//
+// Passing nil disables them again, so ID returns "".
+func SetIDGenerator(gen IDGenerator) {
+	for {
+		old := instance.Load()
+		if instance.CompareAndSwap(old, &instanceConfig{now: old.now, newID: gen}) {
+			return
+		}
+	}
+}
+
+// stamp sets the creation time and instance ID of a new error.
+func (e *Error) stamp() {
+	cfg := instance.Load()
+	e.time = cfg.now()
+	if cfg.newID != nil {
+		e.id = cfg.newID(e.time)
+	}
+}
+
+// ID returns the unique instance ID assigned when the error was created, or ""
+// if instance IDs are disabled. Unlike the code and reason, which identify a
+// kind of error, the ID identifies one occurrence: it is safe to show to clients
+// and lets a reported error be found in the logs.
+func (e *Error) ID() string {
+	if e == nil {
+		return ""
+	}
+	return e.id
+}
+
+// Time returns when the error was created.
+func (e *Error) Time() time.Time {
+	if e == nil {
+		return time.Time{}
+	}
+	return e.time
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidSource keeps IDs generated within the same millisecond increasing.
+var ulidSource struct {
+	mu      sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// NewID returns a ULID for t: 26 Crockford base32 characters encoding the Unix
+// time in milliseconds followed by 80 random bits. IDs sort lexically by time,
+// and IDs generated in the same millisecond by this process sort in generation
+// order.
+func NewID(t time.Time) string {
+	ms := uint64(max(t.UnixMilli(), 0)) & (1<<48 - 1)
+
+	ulidSource.mu.Lock()
+	if ms == ulidSource.ms && increment(&ulidSource.entropy) {
+		// Same millisecond: the incremented entropy keeps the order.
+	} else {
+		ulidSource.ms = ms
+		_, _ = rand.Read(ulidSource.entropy[:])
+	}
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], ulidSource.entropy[:])
+	ulidSource.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+// increment adds one to the big-endian entropy and reports false on overflow.
+func increment(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID encodes the 128 bits of id as 26 base32 characters, the first of
+// which carries only the top 3 bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
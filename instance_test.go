@@ -0,0 +1,95 @@
+package errx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+var ulidPattern = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+type instanceSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestInstanceSuite(t *testing.T) {
+	suite.Run(t, new(instanceSuite))
+}
+
+func (s *instanceSuite) SetupTest() {
+	s.now = time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC)
+	errx.SetClock(func() time.Time { return s.now })
+}
+
+func (s *instanceSuite) TearDownTest() {
+	errx.SetClock(nil)
+	errx.SetIDGenerator(nil)
+}
+
+func (s *instanceSuite) TestDisabledByDefault() {
+	err := errx.NewNotFound("user not found")
+	s.Empty(err.ID())
+	s.Equal(s.now, err.Time())
+	s.NotContains(err.DebugMessage(), "id=")
+}
+
+func (s *instanceSuite) TestNewID() {
+	errx.SetIDGenerator(errx.NewID)
+
+	err := errx.Wrap(errx.NewInternal("db down"), errx.CodeUnavailable, "try later")
+	s.Regexp(ulidPattern, err.ID())
+	cause, ok := errx.As(err.Unwrap())
+	s.Require().True(ok)
+	s.NotEqual(cause.ID(), err.ID())
+	s.Less(cause.ID(), err.ID(), "IDs in the same millisecond sort in creation order")
+}
+
+func (s *instanceSuite) TestNewIDSortsByTime() {
+	ids := []string{
+		errx.NewID(time.UnixMilli(1469918176385)),
+		errx.NewID(time.UnixMilli(1469918176386)),
+		errx.NewID(time.UnixMilli(1700000000000)),
+	}
+	s.True(strings.HasPrefix(ids[0], "01ARYZ6S41"), ids[0])
+	s.True(slices.IsSorted(ids))
+}
+
+func (s *instanceSuite) TestDeterministicGenerator() {
+	n := 0
+	errx.SetIDGenerator(func(t time.Time) string {
+		n++
+		return t.Format("20060102") + "-" + strings.Repeat("x", n)
+	})
+
+	err := errx.NewNotFound("user not found")
+	s.Equal("20260504-x", err.ID())
+	s.Contains(err.DebugMessage(), "id=20260504-x | time=2026-05-04T03:02:01Z")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", err)
+	var entry struct {
+		Error struct {
+			ID   string    `json:"id"`
+			Time time.Time `json:"time"`
+		} `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("20260504-x", entry.Error.ID)
+	s.True(entry.Error.Time.Equal(s.now))
+}
+
+func (s *instanceSuite) TestNil() {
+	var err *errx.Error
+	s.Empty(err.ID())
+	s.True(err.Time().IsZero())
+}
@@ -0,0 +1,92 @@
+package errx
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// Localizer translates message keys into client-facing messages.
+//
+// Localize returns the message for key in the language identified by the BCP 47
+// tag lang (for example "en", "pt-BR"), with params substituted, and reports
+// whether a translation was found. Implementations must be safe for concurrent
+// use. Package github.com/bjaus/errx/i18n provides a Localizer backed by
+// per-locale message catalogs.
+type Localizer interface {
+	Localize(lang, key string, params map[string]any) (string, bool)
+}
+
+// localizerHolder wraps a Localizer so it can be stored in an atomic.Pointer.
+type localizerHolder struct {
+	l Localizer
+}
+
+var defaultLocalizer atomic.Pointer[localizerHolder]
+
+// SetLocalizer sets the Localizer used by [Error.LocalizedMessage] and by
+// renderers such as errxhttp.WriteError. Passing nil disables localization.
+func SetLocalizer(l Localizer) {
+	defaultLocalizer.Store(&localizerHolder{l: l})
+}
+
+// DefaultLocalizer returns the Localizer set with SetLocalizer, or nil.
+func DefaultLocalizer() Localizer {
+	if h := defaultLocalizer.Load(); h != nil {
+		return h.l
+	}
+	return nil
+}
+
+// WithMessageKey sets the key identifying the client message in message
+// catalogs, and the parameters substituted into the translated message.
+// Details are also available as parameters; params take precedence over details
+// with the same name. The message passed to New or Wrap remains the fallback
+// returned by Error.
+func (e *Error) WithMessageKey(key string, params map[string]any) *Error {
+	if e == nil {
+		return nil
+	}
+	e.messageKey = key
+	e.messageParams = params
+	return e
+}
+
+// MessageKey returns the message key set with WithMessageKey.
+func (e *Error) MessageKey() string {
+	if e == nil {
+		return ""
+	}
+	return e.messageKey
+}
+
+// MessageParams returns the parameters used to localize the message: the
+// error's details overlaid with the parameters set with WithMessageKey.
+func (e *Error) MessageParams() map[string]any {
+	if e == nil {
+		return nil
+	}
+	params := make(map[string]any, len(e.details)+len(e.messageParams))
+	maps.Copy(params, e.details)
+	maps.Copy(params, e.messageParams)
+	return params
+}
+
+// LocalizedMessage returns the client message in the language identified by
+// the BCP 47 tag lang, using the Localizer set with SetLocalizer. It falls back
+// to Error if the error has no message key, no Localizer is set, or the key has
+// no translation for lang.
+func (e *Error) LocalizedMessage(lang string) string {
+	if msg, ok := e.Localize(DefaultLocalizer(), lang); ok {
+		return msg
+	}
+	return e.Error()
+}
+
+// Localize translates the client message into lang with l, reporting whether a
+// translation was found. It returns false if e or l is nil or e has no message key.
+func (e *Error) Localize(l Localizer, lang string) (string, bool) {
+	if e == nil || l == nil || e.messageKey == "" {
+		return "", false
+	}
+	return l.Localize(lang, e.messageKey, e.MessageParams())
+}
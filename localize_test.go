@@ -0,0 +1,93 @@
+package errx_test
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+// localizerFunc adapts a function to errx.Localizer.
+type localizerFunc func(lang, key string, params map[string]any) (string, bool)
+
+func (f localizerFunc) Localize(lang, key string, params map[string]any) (string, bool) {
+	return f(lang, key, params)
+}
+
+// germanOnly translates every key into German and nothing else.
+var germanOnly = localizerFunc(func(lang, key string, params map[string]any) (string, bool) {
+	if lang != "de" {
+		return "", false
+	}
+	return fmt.Sprintf("%s auf Deutsch %v", key, params), true
+})
+
+type localizeSuite struct {
+	suite.Suite
+}
+
+func TestLocalizeSuite(t *testing.T) {
+	suite.Run(t, new(localizeSuite))
+}
+
+func (s *localizeSuite) TearDownTest() {
+	errx.SetLocalizer(nil)
+}
+
+func (s *localizeSuite) TestWithMessageKey() {
+	err := errx.NewFailedPrecondition("invite expired").
+		WithDetail("invite_id", "inv-1").
+		WithMessageKey("invite.expired", map[string]any{"days": 7, "invite_id": "override"})
+
+	s.Equal("invite expired", err.Error(), "the message is unchanged")
+	s.Equal("invite.expired", err.MessageKey())
+	s.Equal(map[string]any{"days": 7, "invite_id": "override"}, err.MessageParams(), "params override details")
+	s.Equal(map[string]any{"invite_id": "inv-1"}, err.Details(), "details are not modified")
+}
+
+func (s *localizeSuite) TestLocalizedMessage() {
+	err := errx.NewNotFound("user not found").WithMessageKey("user.not_found", nil).WithDetail("id", 1)
+
+	s.Equal("user not found", err.LocalizedMessage("de"), "no localizer falls back to Error")
+
+	errx.SetLocalizer(germanOnly)
+	s.Equal("user.not_found auf Deutsch map[id:1]", err.LocalizedMessage("de"))
+	s.Equal("user not found", err.LocalizedMessage("fr"), "missing translations fall back to Error")
+	s.Equal("plain", errx.NewNotFound("plain").LocalizedMessage("de"), "errors without a key are not localized")
+}
+
+func (s *localizeSuite) TestLocalize() {
+	err := errx.NewNotFound("user not found").WithMessageKey("user.not_found", nil)
+
+	msg, ok := err.Localize(germanOnly, "de")
+	s.True(ok)
+	s.Equal("user.not_found auf Deutsch map[]", msg)
+
+	_, ok = err.Localize(nil, "de")
+	s.False(ok)
+
+	var nilErr *errx.Error
+	_, ok = nilErr.Localize(germanOnly, "de")
+	s.False(ok)
+	s.Empty(nilErr.LocalizedMessage("de"))
+	s.Nil(nilErr.WithMessageKey("k", nil))
+	s.Empty(nilErr.MessageKey())
+	s.Nil(nilErr.MessageParams())
+}
+
+func (s *localizeSuite) TestDefaultLocalizer() {
+	s.Nil(errx.DefaultLocalizer())
+	errx.SetLocalizer(germanOnly)
+	s.NotNil(errx.DefaultLocalizer())
+}
+
+func (s *localizeSuite) TestMessageKeyInDebugMessageAndLogValue() {
+	err := errx.NewNotFound("user not found").WithMessageKey("user.not_found", nil)
+	s.Contains(err.DebugMessage(), "message_key=user.not_found")
+
+	attrs := err.LogValue().Group()
+	s.Contains(attrs, slog.String("message_key", "user.not_found"))
+}
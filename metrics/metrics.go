@@ -0,0 +1,222 @@
+// Package metrics counts errors by errx code, fault, source and reason, and
+// exposes the counts through expvar and the Prometheus text exposition format
+// without depending on a metrics library.
+//
+//	metrics.Observe(err)                // wherever errors are handled
+//	errx.OnCreate(metrics.Default.Hook) // or as errors are created
+//
+//	mux.Handle("/metrics/errors", metrics.Default)
+//	metrics.Default.Publish("errx_errors")   // expvar, under /debug/vars
+//
+// The handler serves a single counter:
+//
+//	# HELP errx_errors_total Errors observed, by errx code, fault, source and reason.
+//	# TYPE errx_errors_total counter
+//	errx_errors_total{code="not_found",fault="client",source="users",reason="USER_DELETED"} 12
+//
+// The fault label, from errx.FaultOf, splits client mistakes from server
+// failures for SLOs.
+//
+// Sources and reasons are unbounded, so the number of series is capped (see
+// WithMaxSeries). Once the cap is reached, errors of new source and reason
+// combinations are counted under their code with OverflowLabel as source and
+// reason, which keeps per-code rates exact.
+package metrics
+
+import (
+	"cmp"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bjaus/errx"
+)
+
+// Defaults for a Counter.
+const (
+	DefaultName      = "errx_errors_total"
+	DefaultMaxSeries = 1000
+)
+
+// OverflowLabel is the source and reason of the series counting errors beyond
+// the series cap.
+const OverflowLabel = "__overflow__"
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Default is the Counter used by Observe.
+var Default = New()
+
+// Observe counts err with the Default counter.
+func Observe(err error) {
+	Default.Observe(err)
+}
+
+// Option configures a Counter.
+type Option func(*Counter)
+
+// WithName sets the metric name used in the Prometheus exposition.
+func WithName(name string) Option {
+	return func(c *Counter) {
+		c.name = name
+	}
+}
+
+// WithMaxSeries caps the number of code, fault, source and reason combinations
+// counted separately. Values below 1 are treated as 1. The overflow series, one
+// per code and fault, come on top of the cap.
+func WithMaxSeries(n int) Option {
+	return func(c *Counter) {
+		c.maxSeries = max(n, 1)
+	}
+}
+
+// Series is the count of one code, fault, source and reason combination.
+type Series struct {
+	Code   string `json:"code"`
+	Fault  string `json:"fault"`
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	Count  uint64 `json:"count"`
+}
+
+// key identifies a series.
+type key struct {
+	code, fault, source, reason string
+}
+
+// Counter counts errors by code, fault, source and reason. It is safe for concurrent
+// use; counting an error of an existing series takes no lock.
+type Counter struct {
+	name      string
+	maxSeries int
+
+	series sync.Map // key -> *atomic.Uint64
+
+	mu sync.Mutex // serializes series creation
+	n  int        // series created, excluding overflow series
+}
+
+// New creates a Counter.
+func New(opts ...Option) *Counter {
+	c := &Counter{name: DefaultName, maxSeries: DefaultMaxSeries}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Observe counts err under the code and fault of err and the source and reason
+// of the outermost *Error in its chain. Nil errors are ignored.
+func (c *Counter) Observe(err error) {
+	if err == nil {
+		return
+	}
+	k := key{code: errx.CodeOf(err).String(), fault: errx.FaultOf(err).String()}
+	if e, ok := errx.As(err); ok {
+		k.source = e.Source()
+		k.reason = e.Reason()
+	}
+	c.counter(k).Add(1)
+}
+
+// Hook counts errors as they are created. Register it with errx.OnCreate:
+//
+//	errx.OnCreate(metrics.Default.Hook)
+//
+// Only originating errors are counted; errors wrapping another *Error were
+// counted when their cause was created. Since creation hooks run before methods
+// such as WithSource and WithReason, prefer Observe where errors are handled if
+// sources and reasons matter.
+func (c *Counter) Hook(e *errx.Error) {
+	if _, ok := errx.As(e.Unwrap()); ok {
+		return
+	}
+	c.Observe(e)
+}
+
+// counter returns the counter of series k, creating it if needed.
+func (c *Counter) counter(k key) *atomic.Uint64 {
+	if v, ok := c.series.Load(k); ok {
+		return v.(*atomic.Uint64)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.series.Load(k); ok {
+		return v.(*atomic.Uint64)
+	}
+	if c.n >= c.maxSeries {
+		k = key{code: k.code, fault: k.fault, source: OverflowLabel, reason: OverflowLabel}
+		if v, ok := c.series.Load(k); ok {
+			return v.(*atomic.Uint64)
+		}
+	} else {
+		c.n++
+	}
+	v := new(atomic.Uint64)
+	c.series.Store(k, v)
+	return v
+}
+
+// Snapshot returns the current counts, ordered by code, fault, source and reason.
+func (c *Counter) Snapshot() []Series {
+	var out []Series
+	c.series.Range(func(k, v any) bool {
+		k2 := k.(key)
+		out = append(out, Series{Code: k2.code, Fault: k2.fault, Source: k2.source, Reason: k2.reason, Count: v.(*atomic.Uint64).Load()})
+		return true
+	})
+	slices.SortFunc(out, func(a, b Series) int {
+		return cmp.Or(cmp.Compare(a.Code, b.Code), cmp.Compare(a.Fault, b.Fault), cmp.Compare(a.Source, b.Source), cmp.Compare(a.Reason, b.Reason))
+	})
+	return out
+}
+
+// Reset removes every series.
+func (c *Counter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series.Clear()
+	c.n = 0
+}
+
+// Publish exports the counts as the expvar variable name, a list of Series.
+// Like expvar.Publish, it panics if the name is already registered.
+func (c *Counter) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return c.Snapshot() }))
+}
+
+// WritePrometheus writes the counts in the Prometheus text exposition format.
+func (c *Counter) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s Errors observed, by errx code, fault, source and reason.\n", c.name)
+	fmt.Fprintf(&b, "# TYPE %s counter\n", c.name)
+	for _, s := range c.Snapshot() {
+		fmt.Fprintf(&b, "%s{code=%s,fault=%s,source=%s,reason=%s} %d\n",
+			c.name, labelValue(s.Code), labelValue(s.Fault), labelValue(s.Source), labelValue(s.Reason), s.Count)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP implements http.Handler, serving the counts in the Prometheus text
+// exposition format.
+func (c *Counter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = c.WritePrometheus(w)
+}
+
+// labelReplacer escapes label values as required by the exposition format.
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue quotes and escapes a label value.
+func labelValue(s string) string {
+	return `"` + labelReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,124 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/metrics"
+)
+
+type metricsSuite struct {
+	suite.Suite
+	c *metrics.Counter
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(metricsSuite))
+}
+
+func (s *metricsSuite) SetupTest() {
+	s.c = metrics.New()
+}
+
+func (s *metricsSuite) TestObserve() {
+	s.c.Observe(errx.NewNotFound("user not found").WithSource("users").WithReason("USER_DELETED"))
+	s.c.Observe(errx.NewNotFound("user not found").WithSource("users").WithReason("USER_DELETED"))
+	s.c.Observe(errx.Wrap(errx.NewInternal("db").WithSource("db"), errx.CodeUnavailable, "try later"))
+	s.c.Observe(errors.New("boom"))
+	s.c.Observe(errx.Wrap(errx.NewNotFound("missing"), errx.CodeUnknown, "lookup failed"))
+	s.c.Observe(nil)
+
+	s.Equal([]metrics.Series{
+		{Code: "not_found", Fault: "client", Source: "users", Reason: "USER_DELETED", Count: 2},
+		{Code: "unavailable", Fault: "server", Count: 1},
+		{Code: "unknown", Fault: "client", Count: 1},
+		{Code: "unknown", Fault: "unknown", Count: 1},
+	}, s.c.Snapshot())
+}
+
+func (s *metricsSuite) TestOverflow() {
+	c := metrics.New(metrics.WithMaxSeries(2))
+	for i := range 5 {
+		c.Observe(errx.NewInternal("boom").WithSource(fmt.Sprint("svc-", i)))
+	}
+	c.Observe(errx.NewNotFound("missing").WithSource("svc-9"))
+	c.Observe(errx.NewInternal("boom").WithSource("svc-0"))
+
+	s.Equal([]metrics.Series{
+		{Code: "internal", Fault: "server", Source: metrics.OverflowLabel, Reason: metrics.OverflowLabel, Count: 3},
+		{Code: "internal", Fault: "server", Source: "svc-0", Count: 2},
+		{Code: "internal", Fault: "server", Source: "svc-1", Count: 1},
+		{Code: "not_found", Fault: "client", Source: metrics.OverflowLabel, Reason: metrics.OverflowLabel, Count: 1},
+	}, c.Snapshot())
+}
+
+func (s *metricsSuite) TestPrometheusHandler() {
+	c := metrics.New(metrics.WithName("app_errors_total"))
+	c.Observe(errx.NewInvalidArgument("bad").WithSource(`a"b\c` + "\n"))
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	s.Equal(metrics.ContentType, rec.Header().Get("Content-Type"))
+	s.Equal(`# HELP app_errors_total Errors observed, by errx code, fault, source and reason.
+# TYPE app_errors_total counter
+app_errors_total{code="invalid_argument",fault="client",source="a\"b\\c\n",reason=""} 1
+`, rec.Body.String())
+}
+
+func (s *metricsSuite) TestPublish() {
+	s.c.Observe(errx.NewInternal("boom"))
+	s.c.Publish("errx_metrics_test")
+
+	var series []metrics.Series
+	s.Require().NoError(json.Unmarshal([]byte(expvar.Get("errx_metrics_test").String()), &series))
+	s.Equal([]metrics.Series{{Code: "internal", Fault: "server", Count: 1}}, series)
+}
+
+func (s *metricsSuite) TestHook() {
+	defer errx.OnCreate(s.c.Hook)()
+
+	errx.Wrap(errx.NewNotFound("user not found"), errx.CodeInternal, "lookup failed")
+	errx.Wrap(errors.New("refused"), errx.CodeUnavailable, "db down")
+
+	s.Equal([]metrics.Series{
+		{Code: "not_found", Fault: "client", Count: 1},
+		{Code: "unavailable", Fault: "server", Count: 1},
+	}, s.c.Snapshot())
+}
+
+func (s *metricsSuite) TestReset() {
+	s.c.Observe(errx.NewInternal("boom"))
+	s.c.Reset()
+	s.Empty(s.c.Snapshot())
+}
+
+func (s *metricsSuite) TestConcurrentObserve() {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				s.c.Observe(errx.NewInternal("boom").WithSource(fmt.Sprint("svc-", i%2)))
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total uint64
+	for _, series := range s.c.Snapshot() {
+		total += series.Count
+	}
+	s.Equal(uint64(800), total)
+	s.Len(s.c.Snapshot(), 2)
+}
@@ -0,0 +1,69 @@
+package errx
+
+import (
+	"slices"
+)
+
+// WithOp appends an operation name, such as "svc.GetUser", to the error's
+// operation trail. Empty names are ignored.
+func (e *Error) WithOp(name string) *Error {
+	if e == nil {
+		return nil
+	}
+	if name != "" {
+		e.ops = append(e.ops, name)
+	}
+	return e
+}
+
+// Ops returns the operation trail of the error and its causes, deepest first,
+// as described by the package-level [Ops].
+func (e *Error) Ops() []string {
+	if e == nil {
+		return nil
+	}
+	return Ops(e)
+}
+
+// Op records that err passed through the operation name, without creating a
+// new wrapper or capturing another stack trace:
+//
+//	user, err := s.repo.FindByID(ctx, id)
+//	if err != nil {
+//	    return nil, errx.Op(err, "svc.GetUser")
+//	}
+//
+// The name is appended to the nearest *Error in err's tree and err itself is
+// returned, so fmt.Errorf wrappers around it are kept. If err contains no *Error,
+// it is wrapped with code unknown and the message "unknown error", as Ensure
+// would, to hold the trail. Op returns nil for a nil error.
+//
+// Like the With methods, Op modifies the error in place: do not call it on
+// errors shared between goroutines or callers, such as package-level sentinels.
+func Op(err error, name string) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := As(err); ok {
+		e.WithOp(name)
+		return err
+	}
+	return newError(CodeUnknown, "unknown error", err).WithOp(name)
+}
+
+// Ops returns the operation trail recorded with Op and WithOp across err's tree,
+// deepest first, so an error returned through repo.FindByID, svc.GetUser and
+// handler.GetUser yields exactly that order.
+func Ops(err error) []string {
+	var levels [][]string
+	for e := range chain(err) {
+		if len(e.ops) > 0 {
+			levels = append(levels, e.ops)
+		}
+	}
+	var ops []string
+	for _, level := range slices.Backward(levels) {
+		ops = append(ops, level...)
+	}
+	return ops
+}
@@ -0,0 +1,117 @@
+package errx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type opSuite struct {
+	suite.Suite
+}
+
+func TestOpSuite(t *testing.T) {
+	suite.Run(t, new(opSuite))
+}
+
+func (s *opSuite) TestTrail() {
+	findByID := func() error { return errx.Op(errx.NewNotFound("user not found"), "repo.FindByID") }
+	getUser := func() error { return errx.Op(findByID(), "svc.GetUser") }
+	handle := func() error { return errx.Op(getUser(), "handler.GetUser") }
+
+	err := handle()
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal([]string{"repo.FindByID", "svc.GetUser", "handler.GetUser"}, e.Ops())
+	s.Nil(e.Unwrap(), "Op must not add a wrapper")
+	s.Equal("user not found", err.Error())
+}
+
+func (s *opSuite) TestKeepsStackTrace() {
+	e := errx.NewInternal("boom")
+	stack := e.StackTrace()
+	s.Same(e, errx.Op(e, "svc.Save"))
+	s.Equal(stack, e.StackTrace())
+}
+
+func (s *opSuite) TestKeepsFmtWrappers() {
+	inner := errx.NewInternal("boom")
+	wrapped := fmt.Errorf("save: %w", inner)
+
+	s.Equal(wrapped, errx.Op(wrapped, "svc.Save"))
+	s.Equal([]string{"svc.Save"}, inner.Ops())
+	s.Equal([]string{"svc.Save"}, errx.Ops(wrapped))
+}
+
+func (s *opSuite) TestAcrossWraps() {
+	inner := errx.Op(errx.NewUnavailable("db down"), "repo.Save")
+	outer := errx.Wrap(inner, errx.CodeInternal, "save failed").WithOp("svc.Save")
+	err := errx.Op(outer, "handler.Save")
+
+	s.Equal([]string{"repo.Save", "svc.Save", "handler.Save"}, errx.Ops(err))
+	s.Equal([]string{"repo.Save"}, errx.Ops(inner))
+}
+
+func (s *opSuite) TestPlainError() {
+	cause := errors.New("connection refused")
+	err := errx.Op(cause, "repo.Save")
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal(errx.CodeUnknown, e.Code())
+	s.Equal("unknown error", e.Error())
+	s.ErrorIs(err, cause)
+	s.Equal([]string{"repo.Save"}, e.Ops())
+
+	s.Equal([]string{"repo.Save", "svc.Save"}, errx.Ops(errx.Op(err, "svc.Save")))
+}
+
+func (s *opSuite) TestNil() {
+	s.NoError(errx.Op(nil, "svc.Save"))
+	s.Nil(errx.Ops(nil))
+	s.Nil(errx.Ops(errors.New("plain")))
+
+	var e *errx.Error
+	s.Nil(e.WithOp("svc.Save"))
+	s.Nil(e.Ops())
+}
+
+func (s *opSuite) TestEmptyNameIgnored() {
+	e := errx.NewInternal("boom").WithOp("")
+	s.Empty(e.Ops())
+}
+
+func (s *opSuite) TestDebugMessage() {
+	inner := errx.Op(errx.NewUnavailable("db down"), "repo.Save")
+	outer := errx.Wrap(inner, errx.CodeInternal, "save failed").WithOp("svc.Save")
+
+	s.Contains(outer.DebugMessage(), "ops=repo.Save > svc.Save")
+	s.NotContains(errx.NewInternal("boom").DebugMessage(), "ops=")
+}
+
+func (s *opSuite) TestLogValue() {
+	inner := errx.Op(errx.NewUnavailable("db down"), "repo.Save")
+	outer := errx.Wrap(inner, errx.CodeInternal, "save failed").WithOp("svc.Save")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("x", "error", outer)
+
+	var entry struct {
+		Error struct {
+			Ops   []string `json:"ops"`
+			Cause struct {
+				Ops []string `json:"ops"`
+			} `json:"cause"`
+		} `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal([]string{"svc.Save"}, entry.Error.Ops)
+	s.Equal([]string{"repo.Save"}, entry.Error.Cause.Ops)
+}
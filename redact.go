@@ -0,0 +1,433 @@
+package errx
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// RedactedText replaces redacted values.
+const RedactedText = "[REDACTED]"
+
+// DefaultDeniedKeys are the metadata and detail keys whose values are redacted by
+// the masked policy. Keys are split into words at punctuation and case changes,
+// and match case-insensitively when their words include those of a denied key,
+// so "user_password", "X-Auth-Token" and "sessionID" are redacted too, but
+// "session_count" is not.
+var DefaultDeniedKeys = []string{
+	"password", "passwd", "secret", "token", "api_key", "apikey",
+	"authorization", "cookie", "session_id", "sessionid", "ssn", "credit_card",
+	"card_number", "cvv",
+}
+
+// DefaultScrubbers replace sensitive patterns inside string values under the
+// masked policy: email addresses, bearer tokens, US social security numbers and
+// card numbers, which are digit sequences starting with 2 to 6 that pass the
+// Luhn check.
+var DefaultScrubbers = []Scrubber{
+	{Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), Replacement: "[EMAIL]"},
+	{Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`), Replacement: "Bearer " + RedactedText},
+	{Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), Replacement: "[SSN]"},
+	{Pattern: regexp.MustCompile(`\b[2-6](?:[ -]?\d){12,18}\b`), Replacement: "[CARD]", Valid: luhn},
+}
+
+// Scrubber replaces every match of Pattern in a string value with Replacement,
+// which may refer to submatches as in regexp.Regexp.ReplaceAllString. If Valid
+// is set, only matches for which it returns true are replaced.
+type Scrubber struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+	Valid       func(match string) bool
+}
+
+// replace applies the scrubber to s.
+func (sc Scrubber) replace(s string) string {
+	if sc.Valid == nil {
+		return sc.Pattern.ReplaceAllString(s, sc.Replacement)
+	}
+	return sc.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+		if !sc.Valid(match) {
+			return match
+		}
+		return sc.Pattern.ReplaceAllString(match, sc.Replacement)
+	})
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card
+// numbers. Other characters are ignored.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			continue
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Redactor removes sensitive data from error output. It masks [Sensitive] values,
+// the values of denied keys in details and metadata, and scrubber matches in
+// strings. The zero value redacts only Sensitive values.
+//
+// The Redactor set with SetRedactor is applied by LogValue, DebugMessage and the
+// errxhttp renderers. Client responses are redacted with [Redactor.Client],
+// which leaves out the scrubbers unless WithClientScrubbing is set.
+type Redactor struct {
+	disabled    bool
+	keys        [][]string // words of the denied keys
+	scrubbers   []Scrubber
+	mask        string // replaces redacted values; RedactedText if empty
+	scrubClient bool   // whether Client keeps the scrubbers
+}
+
+// RedactOption configures a Redactor.
+type RedactOption func(*Redactor)
+
+// WithDeniedKeys adds keys whose values are redacted. A key matches
+// case-insensitively when its words, split at punctuation and case changes,
+// include the words of one of the given keys in order: "api_key" denies
+// "X-Api-Key" and "apiKey" but not "api_version".
+func WithDeniedKeys(keys ...string) RedactOption {
+	return func(r *Redactor) {
+		for _, k := range keys {
+			if words := keyWords(k); len(words) > 0 {
+				r.keys = append(r.keys, words)
+			}
+		}
+	}
+}
+
+// WithScrubber adds a scrubber replacing matches of pattern in string values.
+func WithScrubber(pattern *regexp.Regexp, replacement string) RedactOption {
+	return func(r *Redactor) {
+		r.scrubbers = append(r.scrubbers, Scrubber{Pattern: pattern, Replacement: replacement})
+	}
+}
+
+// WithClientScrubbing applies the scrubbers to client responses as well as to
+// logs and debug output. By default, client messages and details are only
+// redacted by key and for Sensitive values, since scrubbers can match ordinary
+// data such as order numbers in messages written for the client.
+func WithClientScrubbing() RedactOption {
+	return func(r *Redactor) {
+		r.scrubClient = true
+	}
+}
+
+// WithMask sets the text replacing redacted values (default [RedactedText]).
+func WithMask(mask string) RedactOption {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// NewRedactor creates a Redactor with the given options and no defaults.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	r := &Redactor{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// MaskedRedactor returns the production policy: DefaultDeniedKeys and
+// DefaultScrubbers, plus any extra options.
+func MaskedRedactor(opts ...RedactOption) *Redactor {
+	r := NewRedactor(WithDeniedKeys(DefaultDeniedKeys...))
+	r.scrubbers = append(r.scrubbers, DefaultScrubbers...)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// FullRedactor returns the development policy, which shows everything,
+// including Sensitive values.
+func FullRedactor() *Redactor {
+	return &Redactor{disabled: true}
+}
+
+// RedactorFor returns the policy for an environment name: FullRedactor for
+// "dev", "development", "local" and "test", and MaskedRedactor otherwise.
+func RedactorFor(env string) *Redactor {
+	switch strings.ToLower(env) {
+	case "dev", "development", "local", "test":
+		return FullRedactor()
+	default:
+		return MaskedRedactor()
+	}
+}
+
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	defaultRedactor.Store(MaskedRedactor())
+}
+
+// SetRedactor sets the Redactor applied to error output. The default is
+// MaskedRedactor. Passing nil selects FullRedactor.
+func SetRedactor(r *Redactor) {
+	if r == nil {
+		r = FullRedactor()
+	}
+	defaultRedactor.Store(r)
+}
+
+// DefaultRedactor returns the Redactor set with SetRedactor.
+func DefaultRedactor() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// Enabled reports whether the Redactor redacts anything.
+func (r *Redactor) Enabled() bool {
+	return r != nil && !r.disabled
+}
+
+// Client returns the Redactor for client responses: r without its scrubbers,
+// unless r was created with WithClientScrubbing.
+func (r *Redactor) Client() *Redactor {
+	if !r.Enabled() || r.scrubClient || len(r.scrubbers) == 0 {
+		return r
+	}
+	c := *r
+	c.scrubbers = nil
+	return &c
+}
+
+// Denied reports whether values under key are redacted.
+func (r *Redactor) Denied(key string) bool {
+	if !r.Enabled() || len(r.keys) == 0 {
+		return false
+	}
+	words := keyWords(key)
+	for _, k := range r.keys {
+		for i := 0; i+len(k) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(k)], k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyWords splits key into lower-cased words at non-alphanumeric characters and
+// case changes, so "X-Auth-Token", "authToken" and "APIKey" yield ["x" "auth"
+// "token"], ["auth" "token"] and ["api" "key"].
+func keyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := -1
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(c) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// Scrub applies the scrubbers to s.
+func (r *Redactor) Scrub(s string) string {
+	if !r.Enabled() {
+		return s
+	}
+	for _, sc := range r.scrubbers {
+		s = sc.replace(s)
+	}
+	return s
+}
+
+// Value redacts a value stored under key: Sensitive values and values of denied
+// keys are replaced with the mask, strings are scrubbed, and nested maps and
+// string slices are redacted recursively. Other values are returned unchanged.
+func (r *Redactor) Value(key string, v any) any {
+	if !r.Enabled() {
+		return v
+	}
+	if _, ok := v.(sensitive); ok || r.Denied(key) {
+		return r.maskText()
+	}
+	switch v := v.(type) {
+	case string:
+		return r.Scrub(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = r.Scrub(s)
+		}
+		return out
+	case map[string]any:
+		return r.Map(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.Denied(k) {
+				out[k] = r.maskText()
+			} else {
+				out[k] = r.Scrub(s)
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// maskText returns the text replacing redacted values.
+func (r *Redactor) maskText() string {
+	if r.mask == "" {
+		return RedactedText
+	}
+	return r.mask
+}
+
+// Map returns a redacted copy of m, or m itself if redaction is disabled.
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if !r.Enabled() || len(m) == 0 {
+		return m
+	}
+	out := maps.Clone(m)
+	for k, v := range m {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// Message returns err.Error() with sensitive data removed: the placeholders of
+// templated *Error messages in err's tree are filled from redacted details
+// instead of the raw ones, and the scrubbers are applied to the result. Use it
+// wherever an error message is rendered for logs, reports or clients.
+func (r *Redactor) Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	s := err.Error()
+	if !r.Enabled() {
+		return s
+	}
+	for e := range chain(err) {
+		if !e.templated || len(e.details) == 0 {
+			continue
+		}
+		raw := Interpolate(e.message, e.details)
+		if redacted := Interpolate(e.message, r.Map(e.details)); redacted != raw {
+			s = strings.ReplaceAll(s, raw, redacted)
+		}
+	}
+	return r.Scrub(s)
+}
+
+// Localize translates the client message of e into lang with l, like
+// (*Error).Localize, but with its parameters redacted and the scrubbers applied
+// to the translation.
+func (r *Redactor) Localize(e *Error, l Localizer, lang string) (string, bool) {
+	if e == nil || l == nil || e.messageKey == "" {
+		return "", false
+	}
+	msg, ok := l.Localize(lang, e.messageKey, r.Map(e.MessageParams()))
+	if !ok {
+		return "", false
+	}
+	return r.Scrub(msg), true
+}
+
+// sensitive is implemented by Sensitive values regardless of their type parameter.
+type sensitive interface {
+	sensitive()
+}
+
+// Sensitive wraps a value so that it is masked wherever it is printed, logged or
+// serialized, unless the current Redactor shows everything (see FullRedactor):
+//
+//	errx.NewUnauthenticated("login failed").WithMeta("password", errx.Mask(pw))
+//
+// Use Value to get the wrapped value back.
+type Sensitive[T any] struct {
+	value T
+}
+
+// Mask wraps v as a Sensitive value.
+func Mask[T any](v T) Sensitive[T] {
+	return Sensitive[T]{value: v}
+}
+
+func (Sensitive[T]) sensitive() {}
+
+// Value returns the wrapped value.
+func (s Sensitive[T]) Value() T {
+	return s.value
+}
+
+// String implements fmt.Stringer.
+func (s Sensitive[T]) String() string {
+	r := DefaultRedactor()
+	if !r.Enabled() {
+		return fmt.Sprint(s.value)
+	}
+	return r.maskText()
+}
+
+// GoString implements fmt.GoStringer, so %#v is masked too.
+func (s Sensitive[T]) GoString() string {
+	return s.String()
+}
+
+// Format implements fmt.Formatter, masking the value for every verb.
+func (s Sensitive[T]) Format(f fmt.State, verb rune) {
+	r := DefaultRedactor()
+	if !r.Enabled() {
+		fmt.Fprintf(f, fmt.FormatString(f, verb), s.value)
+		return
+	}
+	_, _ = fmt.Fprint(f, r.maskText())
+}
+
+// LogValue implements slog.LogValuer.
+func (s Sensitive[T]) LogValue() slog.Value {
+	r := DefaultRedactor()
+	if !r.Enabled() {
+		return slog.AnyValue(s.value)
+	}
+	return slog.StringValue(r.maskText())
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Sensitive[T]) MarshalJSON() ([]byte, error) {
+	r := DefaultRedactor()
+	if !r.Enabled() {
+		return json.Marshal(s.value)
+	}
+	return json.Marshal(r.maskText())
+}
@@ -0,0 +1,220 @@
+package errx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type redactSuite struct {
+	suite.Suite
+}
+
+func TestRedactSuite(t *testing.T) {
+	suite.Run(t, new(redactSuite))
+}
+
+func (s *redactSuite) TearDownTest() {
+	errx.SetRedactor(errx.MaskedRedactor())
+}
+
+func (s *redactSuite) TestDefaultIsMasked() {
+	s.True(errx.DefaultRedactor().Enabled())
+	s.True(errx.DefaultRedactor().Denied("user_password"))
+	s.True(errx.DefaultRedactor().Denied("X-Auth-Token"))
+	s.False(errx.DefaultRedactor().Denied("user_id"))
+}
+
+func (s *redactSuite) TestDeniedKeysMatchWords() {
+	r := errx.DefaultRedactor()
+	for _, key := range []string{"password", "userPassword", "X-Api-Key", "APIKey", "session_id", "sessionID", "auth.token"} {
+		s.True(r.Denied(key), key)
+	}
+	for _, key := range []string{"session_count", "tokenizer", "api_version", "cookies_enabled", "assn"} {
+		s.False(r.Denied(key), key)
+	}
+}
+
+func (s *redactSuite) TestCardScrubberChecksLuhn() {
+	r := errx.DefaultRedactor()
+	s.Equal("paid with [CARD]", r.Scrub("paid with 4111 1111 1111 1111"))
+	s.Equal("paid with [CARD]", r.Scrub("paid with 5500-0000-0000-0004"))
+	s.Equal("order 1234567890123 failed", r.Scrub("order 1234567890123 failed"))
+	s.Equal("ref 4111111111111112", r.Scrub("ref 4111111111111112"), "fails the Luhn check")
+	s.Equal("at 1760803200123456789", r.Scrub("at 1760803200123456789"))
+}
+
+func (s *redactSuite) TestClient() {
+	r := errx.MaskedRedactor()
+	c := r.Client()
+	s.Equal("bob@example.com", c.Scrub("bob@example.com"), "scrubbers are left out")
+	s.True(c.Denied("password"), "keys are still denied")
+	s.Equal("[EMAIL]", r.Scrub("bob@example.com"))
+
+	scrubbing := errx.MaskedRedactor(errx.WithClientScrubbing())
+	s.Equal("[EMAIL]", scrubbing.Client().Scrub("bob@example.com"))
+	s.False(errx.FullRedactor().Client().Enabled())
+}
+
+func (s *redactSuite) TestSensitive() {
+	pw := errx.Mask("hunter2")
+	s.Equal("hunter2", pw.Value())
+	s.Equal(errx.RedactedText, pw.String())
+	s.Equal("[REDACTED] [REDACTED] [REDACTED] [REDACTED]", fmt.Sprintf("%v %s %q %#v", pw, pw, pw, pw))
+	s.Equal(slog.StringValue(errx.RedactedText), pw.LogValue())
+
+	data, err := json.Marshal(map[string]any{"pw": pw})
+	s.Require().NoError(err)
+	s.JSONEq(`{"pw":"[REDACTED]"}`, string(data))
+
+	errx.SetRedactor(errx.FullRedactor())
+	s.Equal("hunter2", pw.String())
+	s.Equal(`"hunter2"`, fmt.Sprintf("%q", pw))
+	s.Equal(slog.StringValue("hunter2"), pw.LogValue().Resolve())
+	data, err = json.Marshal(pw)
+	s.Require().NoError(err)
+	s.Equal(`"hunter2"`, string(data))
+}
+
+func (s *redactSuite) TestDebugMessage() {
+	err := errx.Wrap(errors.New("login for bob@example.com failed"), errx.CodeUnauthenticated, "login failed").
+		WithMeta("password", "hunter2").
+		WithMeta("email", errx.Mask("bob@example.com")).
+		WithMeta("note", "ssn 123-45-6789").
+		WithDetail("user_id", "u-1").
+		WithDebug("Authorization: Bearer abc.def.ghi")
+
+	msg := err.DebugMessage()
+	s.NotContains(msg, "hunter2")
+	s.NotContains(msg, "bob@example.com")
+	s.NotContains(msg, "123-45-6789")
+	s.NotContains(msg, "abc.def.ghi")
+	s.Contains(msg, "password:[REDACTED]")
+	s.Contains(msg, "email:[REDACTED]")
+	s.Contains(msg, "note:ssn [SSN]")
+	s.Contains(msg, "user_id:u-1")
+	s.Contains(msg, "debug=Authorization: Bearer [REDACTED]")
+	s.Contains(msg, "cause=login for [EMAIL] failed")
+
+	s.Equal("hunter2", err.Metadata()["password"], "the error itself is not modified")
+}
+
+func (s *redactSuite) TestLogValue() {
+	cause := errx.NewInternal("lookup failed").WithMeta("api_key", "k-123")
+	err := errx.Wrap(cause, errx.CodeUnavailable, "upstream failed").
+		WithDetail("card", "4111 1111 1111 1111").
+		WithMeta("session_id", "s-1")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", err)
+	out := buf.String()
+	s.NotContains(out, "k-123")
+	s.NotContains(out, "4111")
+	s.NotContains(out, "s-1")
+	s.Contains(out, `"card":"[CARD]"`)
+	s.Contains(out, `"api_key":"[REDACTED]"`)
+}
+
+func (s *redactSuite) TestTemplateMessage() {
+	inner := errx.NewTemplate(errx.CodeInvalidArgument, "reset token {token} is invalid").
+		WithDetail("token", "s3cr3t-tok")
+	err := errx.Wrap(fmt.Errorf("reset: %w", inner), errx.CodeUnauthenticated, "reset failed")
+
+	s.Equal("reset token s3cr3t-tok is invalid", inner.Error(), "the error itself is not modified")
+	s.Equal("reset token [REDACTED] is invalid", errx.DefaultRedactor().Message(inner))
+	s.Equal("reset: reset token [REDACTED] is invalid", errx.DefaultRedactor().Message(fmt.Errorf("reset: %w", inner)))
+
+	s.NotContains(inner.DebugMessage(), "s3cr3t-tok")
+	s.Contains(inner.DebugMessage(), "[invalid_argument] reset token [REDACTED] is invalid")
+	s.NotContains(err.DebugMessage(), "s3cr3t-tok")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", err)
+	s.NotContains(buf.String(), "s3cr3t-tok")
+	s.Contains(buf.String(), `"cause":"reset: reset token [REDACTED] is invalid"`)
+
+	buf.Reset()
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", inner)
+	s.NotContains(buf.String(), "s3cr3t-tok")
+	s.Contains(buf.String(), `"message":"reset token [REDACTED] is invalid"`)
+
+	errx.SetRedactor(errx.FullRedactor())
+	s.Equal("reset token s3cr3t-tok is invalid", errx.DefaultRedactor().Message(inner))
+}
+
+func (s *redactSuite) TestLocalize() {
+	err := errx.NewInvalidArgument("invalid token").
+		WithDetail("token", "s3cr3t-tok").
+		WithMessageKey("token.invalid", map[string]any{"email": "bob@example.com"})
+	l := localizerFunc(func(_, _ string, params map[string]any) (string, bool) {
+		return fmt.Sprintf("Token %v für %v ungültig", params["token"], params["email"]), true
+	})
+
+	msg, ok := errx.DefaultRedactor().Localize(err, l, "de")
+	s.True(ok)
+	s.Equal("Token [REDACTED] für [EMAIL] ungültig", msg)
+
+	_, ok = errx.DefaultRedactor().Localize(errx.NewInternal("boom"), l, "de")
+	s.False(ok)
+}
+
+func (s *redactSuite) TestFullRedactor() {
+	errx.SetRedactor(errx.RedactorFor("development"))
+	err := errx.NewInternal("failed").WithMeta("password", "hunter2").WithMeta("pin", errx.Mask(1234))
+	s.Contains(err.DebugMessage(), "password:hunter2")
+	s.Contains(err.DebugMessage(), "pin:1234")
+
+	errx.SetRedactor(nil)
+	s.False(errx.DefaultRedactor().Enabled())
+}
+
+func (s *redactSuite) TestRedactorFor() {
+	s.False(errx.RedactorFor("dev").Enabled())
+	s.False(errx.RedactorFor("TEST").Enabled())
+	s.True(errx.RedactorFor("production").Enabled())
+	s.True(errx.RedactorFor("").Enabled())
+}
+
+func (s *redactSuite) TestCustomRedactor() {
+	r := errx.NewRedactor(
+		errx.WithDeniedKeys("Tenant"),
+		errx.WithScrubber(regexp.MustCompile(`acct-(\d+)`), "acct-***"),
+		errx.WithMask("***"),
+	)
+	s.Equal("***", r.Value("tenant_id", "t-1"))
+	s.Equal("see acct-***", r.Value("note", "see acct-42"))
+	s.Equal("password", r.Value("password", "password"), "only configured keys are denied")
+	s.Equal("***", r.Value("any", errx.Mask(1)), "sensitive values are always masked")
+
+	nested := r.Map(map[string]any{
+		"outer":   map[string]any{"tenant": "t-1", "ok": "acct-7"},
+		"headers": map[string]string{"tenant": "t-1"},
+		"list":    []string{"acct-1"},
+		"n":       3,
+	})
+	s.Equal(map[string]any{
+		"outer":   map[string]any{"tenant": "***", "ok": "acct-***"},
+		"headers": map[string]string{"tenant": "***"},
+		"list":    []string{"acct-***"},
+		"n":       3,
+	}, nested)
+}
+
+func (s *redactSuite) TestZeroRedactor() {
+	var r errx.Redactor
+	s.True(r.Enabled())
+	s.Equal("x", r.Value("password", "x"))
+	s.Equal(errx.RedactedText, r.Value("k", errx.Mask("x")), "the zero value only masks sensitive values")
+
+	var nilRedactor *errx.Redactor
+	s.False(nilRedactor.Enabled())
+	s.Equal("x", nilRedactor.Scrub("x"))
+}
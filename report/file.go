@@ -0,0 +1,157 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Default file sink settings.
+const (
+	DefaultMaxSize    = 10 << 20 // 10 MiB
+	DefaultMaxBackups = 3
+)
+
+// FileOption configures a FileSink.
+type FileOption func(*FileSink)
+
+// WithMaxSize sets the size in bytes at which the file is rotated. A batch is
+// never split, so files can exceed it by one batch.
+func WithMaxSize(n int64) FileOption {
+	return func(s *FileSink) {
+		s.maxSize = n
+	}
+}
+
+// WithMaxBackups sets how many rotated files are kept, named "<path>.1" (the
+// most recent) to "<path>.<n>". Zero keeps none.
+func WithMaxBackups(n int) FileOption {
+	return func(s *FileSink) {
+		s.maxBackups = max(n, 0)
+	}
+}
+
+// FileSink appends records to a file as JSON lines, rotating it when it grows
+// past a maximum size.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens, or creates, the file at path for appending.
+func NewFileSink(path string, opts ...FileOption) (*FileSink, error) {
+	s := &FileSink{path: path, maxSize: DefaultMaxSize, maxBackups: DefaultMaxBackups}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens the file for appending and records its size.
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open report file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("open report file: %w", err)
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+// Write implements Sink.
+func (s *FileSink) Write(_ context.Context, records []Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("encode report record: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errors.New("report file is closed")
+	}
+	var rotateErr error
+	if s.size > 0 && s.size+int64(buf.Len()) > s.maxSize {
+		if rotateErr = s.rotate(); s.file == nil {
+			return rotateErr
+		}
+	}
+	n, err := s.file.Write(buf.Bytes())
+	s.size += int64(n)
+	if err != nil {
+		return errors.Join(rotateErr, fmt.Errorf("write report file: %w", err))
+	}
+	return rotateErr
+}
+
+// rotate shifts the backups, moves the current file to "<path>.1" and opens a
+// new file. If the backups cannot be shifted, the file at the current path is
+// reopened, so records keep being appended to it and rotation is retried on
+// the next write. s.file is nil only if reopening fails. The caller must hold
+// s.mu.
+func (s *FileSink) rotate() error {
+	closeErr := s.file.Close()
+	s.file = nil
+	err := s.shift()
+	if openErr := s.open(); openErr != nil {
+		err = errors.Join(err, openErr)
+	}
+	if err = errors.Join(closeErr, err); err != nil {
+		return fmt.Errorf("rotate report file: %w", err)
+	}
+	return nil
+}
+
+// shift moves the current file and its backups one place along, or removes the
+// current file if no backups are kept.
+func (s *FileSink) shift() error {
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupName(s.path, i), backupName(s.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(s.path, backupName(s.path, 1))
+}
+
+// backupName returns the name of the i-th backup of path.
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
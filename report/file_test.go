@@ -0,0 +1,131 @@
+package report_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx/report"
+)
+
+type fileSuite struct {
+	suite.Suite
+	path string
+}
+
+func TestFileSuite(t *testing.T) {
+	suite.Run(t, new(fileSuite))
+}
+
+func (s *fileSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "errors.jsonl")
+}
+
+func (s *fileSuite) records(messages ...string) []report.Record {
+	out := make([]report.Record, len(messages))
+	for i, m := range messages {
+		out[i] = report.Record{Time: time.Unix(0, 0).UTC(), Code: "internal", Message: m}
+	}
+	return out
+}
+
+func (s *fileSuite) read(path string) []string {
+	f, err := os.Open(path)
+	s.Require().NoError(err)
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec report.Record
+		s.Require().NoError(json.Unmarshal(sc.Bytes(), &rec))
+		out = append(out, rec.Message)
+	}
+	return out
+}
+
+func (s *fileSuite) TestWritesJSONLines() {
+	sink, err := report.NewFileSink(s.path)
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Write(context.Background(), s.records("a", "b")))
+	s.Require().NoError(sink.Write(context.Background(), s.records("c")))
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{"a", "b", "c"}, s.read(s.path))
+}
+
+func (s *fileSuite) TestAppendsToExistingFile() {
+	s.Require().NoError(os.WriteFile(s.path, []byte(`{"message":"old"}`+"\n"), 0o644))
+	sink, err := report.NewFileSink(s.path)
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Write(context.Background(), s.records("new")))
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{"old", "new"}, s.read(s.path))
+}
+
+func (s *fileSuite) TestRotation() {
+	line, err := json.Marshal(s.records("0")[0])
+	s.Require().NoError(err)
+	size := int64(len(line) + 1)
+
+	sink, err := report.NewFileSink(s.path, report.WithMaxSize(2*size), report.WithMaxBackups(2))
+	s.Require().NoError(err)
+	for _, m := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		s.Require().NoError(sink.Write(context.Background(), s.records(m)))
+	}
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{"7"}, s.read(s.path))
+	s.Equal([]string{"5", "6"}, s.read(s.path+".1"))
+	s.Equal([]string{"3", "4"}, s.read(s.path+".2"))
+	s.NoFileExists(s.path + ".3")
+}
+
+func (s *fileSuite) TestRotationWithoutBackups() {
+	sink, err := report.NewFileSink(s.path, report.WithMaxSize(1), report.WithMaxBackups(0))
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Write(context.Background(), s.records("a")))
+	s.Require().NoError(sink.Write(context.Background(), s.records("b")))
+	s.Require().NoError(sink.Close())
+
+	s.Equal([]string{"b"}, s.read(s.path))
+	s.NoFileExists(s.path + ".1")
+}
+
+func (s *fileSuite) TestRotationFailureKeepsWriting() {
+	// A non-empty directory where the backup should go makes the rename fail.
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.path+".1", "busy"), 0o755))
+
+	sink, err := report.NewFileSink(s.path, report.WithMaxSize(1), report.WithMaxBackups(1))
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Write(context.Background(), s.records("a")))
+	s.ErrorContains(sink.Write(context.Background(), s.records("b")), "rotate report file")
+	s.Equal([]string{"a", "b"}, s.read(s.path), "records are appended to the current file")
+
+	s.Require().NoError(os.RemoveAll(s.path + ".1"))
+	s.Require().NoError(sink.Write(context.Background(), s.records("c")))
+	s.Require().NoError(sink.Close())
+	s.Equal([]string{"c"}, s.read(s.path))
+	s.Equal([]string{"a", "b"}, s.read(s.path+".1"))
+}
+
+func (s *fileSuite) TestWriteAfterClose() {
+	sink, err := report.NewFileSink(s.path)
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Close())
+	s.NoError(sink.Close())
+	s.Error(sink.Write(context.Background(), s.records("late")))
+}
+
+func (s *fileSuite) TestOpenError() {
+	_, err := report.NewFileSink(filepath.Join(s.path, "missing", "errors.jsonl"))
+	s.Require().Error(err)
+	s.True(strings.HasPrefix(err.Error(), "open report file"))
+}
@@ -0,0 +1,485 @@
+// Package report ships errors to several destinations asynchronously.
+//
+// A Reporter queues reported errors, converts them into redacted Records and
+// writes them in batches to its sinks from a background goroutine, so reporting
+// never waits on slow destinations:
+//
+//	file, err := report.NewFileSink("/var/log/app/errors.jsonl", report.WithMaxSize(50<<20))
+//	...
+//	r := report.New(
+//	    report.WithSink(file),
+//	    report.WithSink(report.NewWebhookSink(alertURL), report.OnlyCodes(errx.CodeInternal, errx.CodeDataLoss)),
+//	    report.WithSink(report.Stderr(), report.ExceptCodes(errx.CodeNotFound)),
+//	)
+//	defer r.Shutdown(context.Background()) // flushes queued errors and closes sinks
+//
+//	r.Report(err)
+//
+// The queue is bounded. When it is full, the DropPolicy decides whether new
+// errors are dropped, the oldest queued error is dropped, or Report blocks until
+// there is room.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// Default reporter settings.
+const (
+	DefaultQueueSize     = 1024
+	DefaultBatchSize     = 64
+	DefaultFlushInterval = time.Second
+)
+
+// Record is the redacted form of a reported error written by sinks. It is built
+// when the error is reported, so later changes to the error do not affect it.
+type Record struct {
+	Time        time.Time      `json:"time"` // When the error was reported
+	ID          string         `json:"id,omitempty"`
+	Code        string         `json:"code"`
+	Reason      string         `json:"reason,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Message     string         `json:"message"`
+	Fingerprint string         `json:"fingerprint"`
+	Tags        []string       `json:"tags,omitempty"`
+	Details     map[string]any `json:"details,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Debug       string         `json:"debug,omitempty"` // errx.Error.DebugMessage, including causes
+
+	// Severity and Causes are for sinks that render the whole cause chain,
+	// such as errxsentry.Sink. They are not encoded.
+	Severity errx.Severity `json:"-"` // errx.SeverityOf the error
+	Causes   []Cause       `json:"-"` // The error and its causes, outermost first
+}
+
+// Cause is the redacted form of one error in a reported error's cause chain.
+type Cause struct {
+	Code     string         // errx code, or empty if the error is not an *errx.Error
+	Reason   string         // errx reason
+	Type     string         // Go type of an error that is not an *errx.Error
+	Message  string         // Redacted message
+	Metadata map[string]any // Redacted metadata of an *errx.Error
+	Stack    []uintptr      // Where an *errx.Error was created, see errx.Error.StackTrace
+}
+
+// NewRecord converts err into a Record reported at t, redacting it with
+// errx.DefaultRedactor.
+func NewRecord(err error, t time.Time) Record {
+	r := errx.DefaultRedactor()
+	rec := Record{
+		Time:        t,
+		Code:        errx.CodeOf(err).String(),
+		Message:     r.Message(err),
+		Fingerprint: errx.Fingerprint(err),
+		Severity:    errx.SeverityOf(err),
+		Causes:      causes(err, r),
+	}
+	if e, ok := errx.As(err); ok {
+		rec.ID = e.ID()
+		rec.Reason = e.Reason()
+		rec.Source = e.Source()
+		rec.Tags = slices.Clone(e.Tags())
+		rec.Details = r.Map(maps.Clone(e.Details()))
+		rec.Metadata = r.Map(maps.Clone(e.Metadata()))
+		rec.Debug = e.DebugMessage()
+	}
+	return rec
+}
+
+// causes returns the redacted *errx.Error values of err's chain, outermost
+// first, followed by the root cause if it is not an *errx.Error. Other
+// wrappers are skipped.
+func causes(err error, r *errx.Redactor) []Cause {
+	var out []Cause
+	for cur := err; cur != nil; cur = errors.Unwrap(cur) {
+		if e, ok := cur.(*errx.Error); ok {
+			out = append(out, Cause{
+				Code:     e.Code().String(),
+				Reason:   e.Reason(),
+				Message:  r.Message(e),
+				Metadata: r.Map(maps.Clone(e.Metadata())),
+				Stack:    e.StackTrace(),
+			})
+			continue
+		}
+		if errors.Unwrap(cur) == nil {
+			out = append(out, Cause{Type: fmt.Sprintf("%T", cur), Message: r.Message(cur)})
+		}
+	}
+	return out
+}
+
+// Sink writes batches of records to a destination. Write is called from a
+// single goroutine. It must not retain records after returning: the slice is
+// reused for later batches, so sinks that send records later must copy them.
+// Sinks that implement io.Closer are closed by Shutdown.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// DropPolicy decides what happens to an error reported while the queue is full.
+type DropPolicy uint8
+
+// Drop policies.
+const (
+	DropNewest DropPolicy = iota // Drop the reported error
+	DropOldest                   // Drop the oldest queued error to make room
+	Block                        // Wait for room, applying backpressure to the caller
+)
+
+// String implements the Stringer interface.
+func (p DropPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	case Block:
+		return "block"
+	default:
+		return "unknown"
+	}
+}
+
+// SinkOption configures how a sink is attached to a Reporter.
+type SinkOption func(*sinkEntry)
+
+// OnlyCodes restricts a sink to errors with one of the given codes.
+func OnlyCodes(codes ...errx.Code) SinkOption {
+	return func(s *sinkEntry) {
+		s.only = append(s.only, codes...)
+	}
+}
+
+// ExceptCodes excludes errors with any of the given codes from a sink.
+func ExceptCodes(codes ...errx.Code) SinkOption {
+	return func(s *sinkEntry) {
+		s.except = append(s.except, codes...)
+	}
+}
+
+// sinkEntry is a sink and its code filter.
+type sinkEntry struct {
+	sink   Sink
+	only   []errx.Code
+	except []errx.Code
+}
+
+// accepts reports whether the sink takes records with code.
+func (s *sinkEntry) accepts(code string) bool {
+	match := func(c errx.Code) bool { return c.String() == code }
+	if len(s.only) > 0 && !slices.ContainsFunc(s.only, match) {
+		return false
+	}
+	return !slices.ContainsFunc(s.except, match)
+}
+
+// Option configures a Reporter.
+type Option func(*Reporter)
+
+// WithSink adds a sink. Every sink receives every batch, filtered by its options.
+func WithSink(sink Sink, opts ...SinkOption) Option {
+	return func(r *Reporter) {
+		entry := &sinkEntry{sink: sink}
+		for _, opt := range opts {
+			opt(entry)
+		}
+		r.sinks = append(r.sinks, entry)
+	}
+}
+
+// WithQueueSize sets how many errors can wait to be written. Values below 1 are
+// treated as 1.
+func WithQueueSize(n int) Option {
+	return func(r *Reporter) {
+		r.queueSize = max(n, 1)
+	}
+}
+
+// WithBatchSize sets the maximum number of records written in one batch; a full
+// batch is written without waiting for the flush interval. Values below 1 are
+// treated as 1.
+func WithBatchSize(n int) Option {
+	return func(r *Reporter) {
+		r.batchSize = max(n, 1)
+	}
+}
+
+// WithFlushInterval sets how often queued errors are written when fewer than the
+// batch size are waiting. Values of zero or below use DefaultFlushInterval.
+func WithFlushInterval(d time.Duration) Option {
+	return func(r *Reporter) {
+		if d <= 0 {
+			d = DefaultFlushInterval
+		}
+		r.interval = d
+	}
+}
+
+// WithDropPolicy sets what happens when the queue is full (default DropNewest).
+func WithDropPolicy(p DropPolicy) Option {
+	return func(r *Reporter) {
+		r.policy = p
+	}
+}
+
+// WithErrorHandler sets a function called when a sink fails to write a batch.
+// The default discards sink errors.
+func WithErrorHandler(fn func(sink Sink, err error)) Option {
+	return func(r *Reporter) {
+		r.onError = fn
+	}
+}
+
+// WithClock sets the time source for record times, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(r *Reporter) {
+		r.now = now
+	}
+}
+
+// Reporter queues errors and writes them to sinks in the background. It is safe
+// for concurrent use.
+//
+// Do not call Report from an errx.OnCreate hook with sinks that create errx
+// errors, such as the webhook sink: their errors would be reported in turn.
+type Reporter struct {
+	sinks     []*sinkEntry
+	queueSize int
+	batchSize int
+	interval  time.Duration
+	policy    DropPolicy
+	onError   func(Sink, error)
+	now       func() time.Time
+
+	queue   chan Record
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	ctx     context.Context // canceled when Shutdown gives up waiting
+	cancel  context.CancelFunc
+	closing sync.Once
+	dropped atomic.Uint64
+
+	// Report takes a read lock to check stop and register its send in
+	// sending; Shutdown closes stop under the write lock. run waits for the
+	// registered sends before its final drain, so no queued record is lost.
+	mu      sync.RWMutex
+	sending sync.WaitGroup
+
+	closeSinksOnce sync.Once
+	closeSinksErr  error
+}
+
+// New creates a Reporter and starts its background goroutine. Call Shutdown to
+// stop it.
+func New(opts ...Option) *Reporter {
+	r := &Reporter{
+		queueSize: DefaultQueueSize,
+		batchSize: DefaultBatchSize,
+		interval:  DefaultFlushInterval,
+		now:       time.Now,
+		flushes:   make(chan chan struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.queue = make(chan Record, r.queueSize)
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	go r.run()
+	return r
+}
+
+// Report queues err and reports whether it was queued. Nil errors are ignored.
+// When the queue is full, the drop policy applies; errors reported after
+// Shutdown are dropped.
+func (r *Reporter) Report(err error) bool {
+	if err == nil {
+		return false
+	}
+	rec := NewRecord(err, r.now())
+
+	r.mu.RLock()
+	select {
+	case <-r.stop:
+		r.mu.RUnlock()
+		r.dropped.Add(1)
+		return false
+	default:
+	}
+	r.sending.Add(1)
+	r.mu.RUnlock()
+	defer r.sending.Done()
+
+	switch r.policy {
+	case Block:
+		select {
+		case r.queue <- rec:
+			return true
+		case <-r.stop:
+			r.dropped.Add(1)
+			return false
+		}
+	case DropOldest:
+		for {
+			select {
+			case r.queue <- rec:
+				return true
+			default:
+			}
+			select {
+			case <-r.queue:
+				r.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case r.queue <- rec:
+			return true
+		default:
+			r.dropped.Add(1)
+			return false
+		}
+	}
+}
+
+// Dropped returns the number of errors dropped by the drop policy or because
+// they were reported after Shutdown.
+func (r *Reporter) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Flush writes the queued errors and waits until they are written or ctx is
+// done.
+func (r *Reporter) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case r.flushes <- ack:
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting errors, writes the queued ones, and closes the sinks
+// that implement io.Closer. If ctx is done first, pending writes are canceled
+// and ctx's error is returned along with any error closing the sinks.
+func (r *Reporter) Shutdown(ctx context.Context) error {
+	r.closing.Do(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		close(r.stop)
+	})
+
+	var err error
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		r.cancel()
+		<-r.done
+		err = ctx.Err()
+	}
+	return errors.Join(err, r.closeSinks())
+}
+
+// closeSinks closes the sinks that implement io.Closer, once.
+func (r *Reporter) closeSinks() error {
+	r.closeSinksOnce.Do(func() {
+		var errs []error
+		for _, s := range r.sinks {
+			if c, ok := s.sink.(io.Closer); ok {
+				errs = append(errs, c.Close())
+			}
+		}
+		r.closeSinksErr = errors.Join(errs...)
+	})
+	return r.closeSinksErr
+}
+
+// run writes queued records until the Reporter is shut down.
+func (r *Reporter) run() {
+	defer close(r.done)
+	defer r.cancel()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, r.batchSize)
+	for {
+		select {
+		case rec := <-r.queue:
+			batch = append(batch, rec)
+			if len(batch) >= r.batchSize {
+				batch = r.write(batch)
+			}
+		case <-ticker.C:
+			batch = r.write(batch)
+		case ack := <-r.flushes:
+			batch = r.drain(batch)
+			close(ack)
+		case <-r.stop:
+			// Sends that started before stop was closed end promptly: Block
+			// gives up on stop, and the other policies do not wait.
+			r.sending.Wait()
+			r.drain(batch)
+			return
+		}
+	}
+}
+
+// drain writes batch and every queued record, in batches.
+func (r *Reporter) drain(batch []Record) []Record {
+	for {
+		select {
+		case rec := <-r.queue:
+			batch = append(batch, rec)
+			if len(batch) >= r.batchSize {
+				batch = r.write(batch)
+			}
+		default:
+			return r.write(batch)
+		}
+	}
+}
+
+// write hands batch to each sink, filtered by its codes, and returns batch
+// emptied for reuse.
+func (r *Reporter) write(batch []Record) []Record {
+	if len(batch) == 0 {
+		return batch
+	}
+	for _, s := range r.sinks {
+		records := batch
+		if len(s.only) > 0 || len(s.except) > 0 {
+			records = slices.DeleteFunc(slices.Clone(batch), func(rec Record) bool { return !s.accepts(rec.Code) })
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err := s.sink.Write(r.ctx, records); err != nil && r.onError != nil {
+			r.onError(s.sink, err)
+		}
+	}
+	clear(batch)
+	return batch[:0]
+}
@@ -0,0 +1,360 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/report"
+)
+
+// memorySink records the batches written to it. When gate is set, Write waits
+// for a value on it (or for ctx) before recording.
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]report.Record
+	closed  bool
+	gate    chan struct{}
+	started chan struct{}
+	err     error
+}
+
+func (m *memorySink) Write(ctx context.Context, records []report.Record) error {
+	if m.gate != nil {
+		select {
+		case m.started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-m.gate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]report.Record(nil), records...))
+	return m.err
+}
+
+func (m *memorySink) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *memorySink) messages() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, b := range m.batches {
+		for _, rec := range b {
+			out = append(out, rec.Message)
+		}
+	}
+	return out
+}
+
+func (m *memorySink) batchSizes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []int
+	for _, b := range m.batches {
+		out = append(out, len(b))
+	}
+	return out
+}
+
+// newGatedSink returns a sink whose writes block until released.
+func newGatedSink() *memorySink {
+	return &memorySink{gate: make(chan struct{}), started: make(chan struct{}, 1)}
+}
+
+type reportSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestReportSuite(t *testing.T) {
+	suite.Run(t, new(reportSuite))
+}
+
+func (s *reportSuite) SetupTest() {
+	s.now = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (s *reportSuite) newReporter(opts ...report.Option) *report.Reporter {
+	opts = append([]report.Option{
+		report.WithFlushInterval(time.Hour),
+		report.WithClock(func() time.Time { return s.now }),
+	}, opts...)
+	r := report.New(opts...)
+	s.T().Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_ = r.Shutdown(ctx)
+	})
+	return r
+}
+
+func (s *reportSuite) TestNewRecord() {
+	err := errx.NewInternal("save failed").
+		WithReason("DB_DOWN").
+		WithSource("orders").
+		WithTags("db").
+		WithMeta("password", "hunter2").
+		WithMeta("table", "orders")
+	rec := report.NewRecord(err, s.now)
+
+	s.Equal(s.now, rec.Time)
+	s.Equal("internal", rec.Code)
+	s.Equal("DB_DOWN", rec.Reason)
+	s.Equal("orders", rec.Source)
+	s.Equal("save failed", rec.Message)
+	s.Equal(errx.Fingerprint(err), rec.Fingerprint)
+	s.Equal([]string{"db"}, rec.Tags)
+	s.Equal("orders", rec.Metadata["table"])
+	s.NotEqual("hunter2", rec.Metadata["password"])
+	s.Contains(rec.Debug, "save failed")
+	s.Equal(errx.SeverityError, rec.Severity)
+	s.Require().Len(rec.Causes, 1)
+	s.Equal("DB_DOWN", rec.Causes[0].Reason)
+	s.Equal(errx.RedactedText, rec.Causes[0].Metadata["password"])
+	s.Equal(err.StackTrace(), rec.Causes[0].Stack)
+
+	err.WithMeta("table", "invoices")
+	s.Equal("orders", rec.Metadata["table"], "the record is a snapshot")
+	s.Equal("orders", rec.Causes[0].Metadata["table"])
+
+	plain := report.NewRecord(io.EOF, s.now)
+	s.Equal("unknown", plain.Code)
+	s.Equal("EOF", plain.Message)
+	s.Empty(plain.Debug)
+	s.Equal([]report.Cause{{Type: "*errors.errorString", Message: "EOF"}}, plain.Causes)
+}
+
+func (s *reportSuite) TestNewRecordCauses() {
+	root := errors.New("connection refused")
+	err := errx.Wrap(fmt.Errorf("load: %w", errx.WrapInternal(root, "query failed")), errx.CodeUnavailable, "db down")
+	rec := report.NewRecord(err, s.now)
+
+	s.Require().Len(rec.Causes, 3)
+	s.Equal("unavailable", rec.Causes[0].Code)
+	s.Equal("query failed", rec.Causes[1].Message)
+	s.Equal("*errors.errorString", rec.Causes[2].Type, "non-errx wrappers are skipped")
+}
+
+func (s *reportSuite) TestNewRecordRedactsTemplateMessage() {
+	err := errx.NewTemplate(errx.CodeInvalidArgument, "reset token {token} is invalid").
+		WithDetail("token", "s3cr3t-tok")
+	rec := report.NewRecord(err, s.now)
+	s.Equal("reset token [REDACTED] is invalid", rec.Message)
+	s.NotContains(rec.Debug, "s3cr3t-tok")
+}
+
+func (s *reportSuite) TestReportAndFlush() {
+	sink := &memorySink{}
+	r := s.newReporter(report.WithSink(sink))
+
+	s.True(r.Report(errx.NewInternal("first")))
+	s.True(r.Report(errx.NewNotFound("second")))
+	s.False(r.Report(nil))
+
+	s.Require().NoError(r.Flush(context.Background()))
+	s.Equal([]string{"first", "second"}, sink.messages())
+}
+
+func (s *reportSuite) TestBatchSize() {
+	sink := &memorySink{}
+	r := s.newReporter(report.WithSink(sink), report.WithBatchSize(2))
+	for range 5 {
+		r.Report(errx.NewInternal("x"))
+	}
+	s.Eventually(func() bool { return len(sink.messages()) >= 4 }, time.Second, time.Millisecond)
+	s.Require().NoError(r.Flush(context.Background()))
+	s.Equal([]int{2, 2, 1}, sink.batchSizes())
+}
+
+func (s *reportSuite) TestFlushInterval() {
+	sink := &memorySink{}
+	r := s.newReporter(report.WithSink(sink), report.WithFlushInterval(5*time.Millisecond))
+	r.Report(errx.NewInternal("tick"))
+	s.Eventually(func() bool { return len(sink.messages()) == 1 }, time.Second, time.Millisecond)
+}
+
+func (s *reportSuite) TestNonPositiveFlushInterval() {
+	for _, d := range []time.Duration{0, -time.Second} {
+		sink := &memorySink{}
+		r := s.newReporter(report.WithSink(sink), report.WithFlushInterval(d))
+		r.Report(errx.NewInternal("tick"))
+		s.Require().NoError(r.Flush(context.Background()))
+		s.Equal([]string{"tick"}, sink.messages())
+	}
+}
+
+func (s *reportSuite) TestSinkCodeFilters() {
+	all, onlyInternal, exceptNotFound := &memorySink{}, &memorySink{}, &memorySink{}
+	r := s.newReporter(
+		report.WithSink(all),
+		report.WithSink(onlyInternal, report.OnlyCodes(errx.CodeInternal)),
+		report.WithSink(exceptNotFound, report.ExceptCodes(errx.CodeNotFound)),
+	)
+	r.Report(errx.NewInternal("internal"))
+	r.Report(errx.NewNotFound("not found"))
+	r.Report(errx.NewUnavailable("unavailable"))
+	s.Require().NoError(r.Flush(context.Background()))
+
+	s.Equal([]string{"internal", "not found", "unavailable"}, all.messages())
+	s.Equal([]string{"internal"}, onlyInternal.messages())
+	s.Equal([]string{"internal", "unavailable"}, exceptNotFound.messages())
+}
+
+func (s *reportSuite) TestDropNewest() {
+	sink := newGatedSink()
+	r := s.newReporter(report.WithSink(sink), report.WithQueueSize(1), report.WithBatchSize(1))
+
+	r.Report(errx.NewInternal("writing"))
+	<-sink.started
+	s.True(r.Report(errx.NewInternal("queued")))
+	s.False(r.Report(errx.NewInternal("dropped")))
+	s.Equal(uint64(1), r.Dropped())
+
+	close(sink.gate)
+	s.Require().NoError(r.Flush(context.Background()))
+	s.Equal([]string{"writing", "queued"}, sink.messages())
+}
+
+func (s *reportSuite) TestDropOldest() {
+	sink := newGatedSink()
+	r := s.newReporter(
+		report.WithSink(sink),
+		report.WithQueueSize(1),
+		report.WithBatchSize(1),
+		report.WithDropPolicy(report.DropOldest),
+	)
+
+	r.Report(errx.NewInternal("writing"))
+	<-sink.started
+	s.True(r.Report(errx.NewInternal("replaced")))
+	s.True(r.Report(errx.NewInternal("newest")))
+	s.Equal(uint64(1), r.Dropped())
+
+	close(sink.gate)
+	s.Require().NoError(r.Flush(context.Background()))
+	s.Equal([]string{"writing", "newest"}, sink.messages())
+}
+
+func (s *reportSuite) TestBlock() {
+	sink := newGatedSink()
+	r := s.newReporter(
+		report.WithSink(sink),
+		report.WithQueueSize(1),
+		report.WithBatchSize(1),
+		report.WithDropPolicy(report.Block),
+	)
+
+	r.Report(errx.NewInternal("writing"))
+	<-sink.started
+	r.Report(errx.NewInternal("queued"))
+
+	reported := make(chan bool)
+	go func() { reported <- r.Report(errx.NewInternal("waiting")) }()
+	select {
+	case <-reported:
+		s.Fail("Report did not block on a full queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(sink.gate)
+	s.True(<-reported)
+	s.Require().NoError(r.Flush(context.Background()))
+	s.Equal([]string{"writing", "queued", "waiting"}, sink.messages())
+	s.Zero(r.Dropped())
+}
+
+func (s *reportSuite) TestDropPolicyString() {
+	s.Equal("drop_newest", report.DropNewest.String())
+	s.Equal("drop_oldest", report.DropOldest.String())
+	s.Equal("block", report.Block.String())
+	s.Equal("unknown", report.DropPolicy(9).String())
+}
+
+func (s *reportSuite) TestErrorHandler() {
+	failing := &memorySink{err: errors.New("disk full")}
+	var mu sync.Mutex
+	var failures []error
+	r := s.newReporter(
+		report.WithSink(failing),
+		report.WithErrorHandler(func(sink report.Sink, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			s.Same(failing, sink)
+			failures = append(failures, err)
+		}),
+	)
+	r.Report(errx.NewInternal("lost"))
+	s.Require().NoError(r.Flush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Require().Len(failures, 1)
+	s.EqualError(failures[0], "disk full")
+}
+
+func (s *reportSuite) TestShutdownFlushesAndCloses() {
+	sink := &memorySink{}
+	r := report.New(report.WithSink(sink), report.WithFlushInterval(time.Hour))
+	r.Report(errx.NewInternal("last words"))
+
+	s.Require().NoError(r.Shutdown(context.Background()))
+	s.Equal([]string{"last words"}, sink.messages())
+	s.True(sink.closed)
+
+	s.False(r.Report(errx.NewInternal("too late")))
+	s.Equal(uint64(1), r.Dropped())
+	s.NoError(r.Flush(context.Background()))
+	s.NoError(r.Shutdown(context.Background()))
+}
+
+func (s *reportSuite) TestReportDuringShutdown() {
+	for _, policy := range []report.DropPolicy{report.DropNewest, report.DropOldest, report.Block} {
+		sink := &memorySink{}
+		r := report.New(report.WithSink(sink), report.WithDropPolicy(policy), report.WithQueueSize(4))
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				for range 50 {
+					r.Report(errx.NewInternal("boom"))
+				}
+			})
+		}
+		s.Require().NoError(r.Shutdown(context.Background()))
+		wg.Wait()
+
+		// Every error is either written or counted as dropped.
+		s.Equal(uint64(400), uint64(len(sink.messages()))+r.Dropped(), policy.String())
+	}
+}
+
+func (s *reportSuite) TestShutdownTimeout() {
+	sink := newGatedSink()
+	r := report.New(report.WithSink(sink), report.WithBatchSize(1))
+	r.Report(errx.NewInternal("stuck"))
+	<-sink.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := r.Shutdown(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.Empty(sink.messages())
+	s.True(sink.closed)
+}
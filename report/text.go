@@ -0,0 +1,69 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TextSink writes records as human-readable text, for development and for
+// services whose stderr is collected:
+//
+//	2026-06-01T10:00:00Z [internal] database unavailable
+//	    source=orders reason=DB_DOWN id=01J... fingerprint=3f9c2a6d1e0b7c54
+//	    debug: [internal] database unavailable | ... | cause=connection refused
+type TextSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTextSink creates a sink writing to w.
+func NewTextSink(w io.Writer) *TextSink {
+	return &TextSink{w: w}
+}
+
+// Stderr returns a TextSink writing to os.Stderr.
+func Stderr() *TextSink {
+	return NewTextSink(os.Stderr)
+}
+
+// Write implements Sink.
+func (s *TextSink) Write(_ context.Context, records []Record) error {
+	var b strings.Builder
+	for _, rec := range records {
+		fmt.Fprintf(&b, "%s [%s] %s\n", rec.Time.UTC().Format(time.RFC3339), rec.Code, rec.Message)
+
+		var fields []string
+		add := func(key, value string) {
+			if value != "" {
+				fields = append(fields, key+"="+value)
+			}
+		}
+		add("source", rec.Source)
+		add("reason", rec.Reason)
+		add("id", rec.ID)
+		add("fingerprint", rec.Fingerprint)
+		if len(rec.Tags) > 0 {
+			add("tags", strings.Join(rec.Tags, ","))
+		}
+		fmt.Fprintf(&b, "    %s\n", strings.Join(fields, " "))
+
+		for _, key := range slices.Sorted(maps.Keys(rec.Metadata)) {
+			fmt.Fprintf(&b, "    %s: %v\n", key, rec.Metadata[key])
+		}
+		if rec.Debug != "" {
+			fmt.Fprintf(&b, "    debug: %s\n", rec.Debug)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
@@ -0,0 +1,58 @@
+package report_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx/report"
+)
+
+type textSuite struct {
+	suite.Suite
+}
+
+func TestTextSuite(t *testing.T) {
+	suite.Run(t, new(textSuite))
+}
+
+func (s *textSuite) TestWrite() {
+	var buf bytes.Buffer
+	sink := report.NewTextSink(&buf)
+	err := sink.Write(context.Background(), []report.Record{
+		{
+			Time:        time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC),
+			ID:          "01J0",
+			Code:        "internal",
+			Reason:      "DB_DOWN",
+			Source:      "orders",
+			Message:     "database unavailable",
+			Fingerprint: "3f9c2a6d1e0b7c54",
+			Tags:        []string{"db", "critical"},
+			Metadata:    map[string]any{"table": "orders", "attempt": 2},
+			Debug:       "[internal] database unavailable | cause=connection refused",
+		},
+		{
+			Time:        time.Date(2026, 6, 1, 10, 0, 1, 0, time.UTC),
+			Code:        "not_found",
+			Message:     "no such order",
+			Fingerprint: "0000000000000000",
+		},
+	})
+	s.Require().NoError(err)
+	s.Equal(`2026-06-01T10:00:00Z [internal] database unavailable
+    source=orders reason=DB_DOWN id=01J0 fingerprint=3f9c2a6d1e0b7c54 tags=db,critical
+    attempt: 2
+    table: orders
+    debug: [internal] database unavailable | cause=connection refused
+2026-06-01T10:00:01Z [not_found] no such order
+    fingerprint=0000000000000000
+`, buf.String())
+}
+
+func (s *textSuite) TestStderr() {
+	s.NotNil(report.Stderr())
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/errxhttp"
+	"github.com/bjaus/errx/retry"
+)
+
+// DefaultMaxRetryDelay caps the retry delay a server may request with
+// Retry-After, so that one response cannot stall the reporter's sinks.
+const DefaultMaxRetryDelay = 10 * time.Second
+
+// retryCodes are the failures of a request posted by a WebhookSink that are retried, in
+// addition to those marked retryable, such as 429 responses with Retry-After.
+var retryCodes = []errx.Code{
+	errx.CodeUnavailable,
+	errx.CodeInternal,
+	errx.CodeDeadlineExceeded,
+	errx.CodeResourceExhausted,
+}
+
+// WebhookOption configures a WebhookSink.
+type WebhookOption func(*WebhookSink)
+
+// WithHTTPClient sets the client used to post batches (default http.DefaultClient).
+func WithHTTPClient(c *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.client = c
+	}
+}
+
+// WithHeader sets a header sent with every request, such as an authorization token.
+func WithHeader(key, value string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.header.Set(key, value)
+	}
+}
+
+// WithService sets the name of the destination used in the errors of failed
+// requests (default "webhook").
+func WithService(name string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.service = name
+	}
+}
+
+// WithRetry sets the retry options for posting a batch. By default a batch is
+// attempted retry.DefaultMaxAttempts times, retrying 5xx and 429 responses and
+// network errors with exponential backoff.
+func WithRetry(opts ...retry.Option) WebhookOption {
+	return func(s *WebhookSink) {
+		s.retryOpts = opts
+	}
+}
+
+// WithMaxRetryDelay caps the delay before retrying a request when the server
+// asks for a longer one with Retry-After (default DefaultMaxRetryDelay). Sinks
+// are written from the reporter's single goroutine, so every sink waits while
+// one sink waits to retry.
+func WithMaxRetryDelay(d time.Duration) WebhookOption {
+	return func(s *WebhookSink) {
+		s.maxRetryDelay = d
+	}
+}
+
+// WebhookSink posts each batch as a JSON array of records to a URL.
+type WebhookSink struct {
+	url           string
+	client        *http.Client
+	header        http.Header
+	service       string
+	retryOpts     []retry.Option
+	maxRetryDelay time.Duration
+}
+
+// NewWebhookSink creates a sink posting to url.
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:           url,
+		client:        http.DefaultClient,
+		header:        make(http.Header),
+		service:       "webhook",
+		maxRetryDelay: DefaultMaxRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Write implements Sink.
+func (s *WebhookSink) Write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("encode report records: %w", err)
+	}
+	return s.Post(ctx, "application/json", body)
+}
+
+// Post sends body with the given content type to the sink's URL, with its
+// headers and retry options. Sinks for other formats use it to share the
+// delivery of WebhookSink. Retry delays requested by the server are capped by
+// WithMaxRetryDelay.
+func (s *WebhookSink) Post(ctx context.Context, contentType string, body []byte) error {
+	opts := append([]retry.Option{
+		retry.WithCodes(retryCodes...),
+		retry.WithDelayHint(s.retryDelay),
+	}, s.retryOpts...)
+	return retry.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return errx.Wrap(err, errx.CodeInvalidArgument, "build "+s.service+" request")
+		}
+		for k, v := range s.header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return errx.Wrap(err, errx.CodeUnavailable, "post "+s.service)
+		}
+		defer resp.Body.Close()
+		return errxhttp.CheckResponse(resp, errxhttp.WithService(s.service))
+	}, opts...)
+}
+
+// retryDelay returns the retry delay requested by the server with err, capped
+// at the sink's maximum.
+func (s *WebhookSink) retryDelay(err error) (time.Duration, bool) {
+	d, ok := errx.RetryDelay(err)
+	if !ok {
+		return 0, false
+	}
+	return min(d, s.maxRetryDelay), true
+}
@@ -0,0 +1,144 @@
+package report_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/report"
+	"github.com/bjaus/errx/retry"
+)
+
+// webhookServer collects the batches posted to it.
+type webhookServer struct {
+	mu         sync.Mutex
+	batches    [][]report.Record
+	headers    []http.Header
+	failures   int    // number of requests to fail with 503 before accepting
+	retryAfter string // Retry-After header of failed requests
+	status     int    // status for every request when set
+}
+
+func (w *webhookServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.headers = append(w.headers, r.Header.Clone())
+	if w.status != 0 {
+		rw.WriteHeader(w.status)
+		return
+	}
+	if w.failures > 0 {
+		w.failures--
+		if w.retryAfter != "" {
+			rw.Header().Set("Retry-After", w.retryAfter)
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var batch []report.Record
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.batches = append(w.batches, batch)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+type webhookSuite struct {
+	suite.Suite
+	hook   *webhookServer
+	server *httptest.Server
+}
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(webhookSuite))
+}
+
+func (s *webhookSuite) SetupTest() {
+	s.hook = &webhookServer{}
+	s.server = httptest.NewServer(s.hook)
+	s.T().Cleanup(s.server.Close)
+}
+
+func (s *webhookSuite) newSink(opts ...report.WebhookOption) *report.WebhookSink {
+	opts = append([]report.WebhookOption{
+		report.WithRetry(retry.WithBaseDelay(time.Millisecond)),
+	}, opts...)
+	return report.NewWebhookSink(s.server.URL, opts...)
+}
+
+func (s *webhookSuite) TestPostsBatch() {
+	sink := s.newSink(report.WithHeader("Authorization", "Bearer secret"))
+	records := []report.Record{
+		{Code: "internal", Message: "a"},
+		{Code: "not_found", Message: "b"},
+	}
+	s.Require().NoError(sink.Write(context.Background(), records))
+
+	s.Require().Len(s.hook.batches, 1)
+	s.Equal(records, s.hook.batches[0])
+	s.Equal("application/json", s.hook.headers[0].Get("Content-Type"))
+	s.Equal("Bearer secret", s.hook.headers[0].Get("Authorization"))
+}
+
+func (s *webhookSuite) TestPost() {
+	s.hook.status = http.StatusUnauthorized
+	sink := s.newSink(report.WithService("collector"))
+	err := sink.Post(context.Background(), "text/plain", []byte("raw"))
+
+	s.True(errx.CodeIs(err, errx.CodeUnauthenticated))
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal("collector", e.Source())
+	s.Equal("text/plain", s.hook.headers[0].Get("Content-Type"))
+}
+
+func (s *webhookSuite) TestRetries() {
+	s.hook.failures = 2
+	sink := s.newSink()
+	s.Require().NoError(sink.Write(context.Background(), []report.Record{{Message: "eventually"}}))
+	s.Len(s.hook.batches, 1)
+	s.Len(s.hook.headers, 3)
+}
+
+func (s *webhookSuite) TestCapsRetryAfter() {
+	s.hook.failures = 1
+	s.hook.retryAfter = "3600"
+	sink := s.newSink(report.WithMaxRetryDelay(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Require().NoError(sink.Write(ctx, []report.Record{{Message: "eventually"}}))
+	s.Len(s.hook.headers, 2)
+}
+
+func (s *webhookSuite) TestGivesUp() {
+	s.hook.failures = 10
+	sink := s.newSink(report.WithRetry(retry.WithMaxAttempts(2), retry.WithBaseDelay(time.Millisecond)))
+	err := sink.Write(context.Background(), []report.Record{{Message: "lost"}})
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+	s.Len(s.hook.headers, 2)
+}
+
+func (s *webhookSuite) TestClientErrorNotRetried() {
+	s.hook.status = http.StatusUnauthorized
+	err := s.newSink().Write(context.Background(), []report.Record{{Message: "denied"}})
+	s.True(errx.CodeIs(err, errx.CodeUnauthenticated))
+	s.Len(s.hook.headers, 1)
+}
+
+func (s *webhookSuite) TestUnreachable() {
+	sink := report.NewWebhookSink("http://127.0.0.1:1",
+		report.WithHTTPClient(&http.Client{Timeout: time.Second}),
+		report.WithRetry(retry.WithMaxAttempts(1)),
+	)
+	err := sink.Write(context.Background(), []report.Record{{Message: "nowhere"}})
+	s.True(errx.CodeIs(err, errx.CodeUnavailable))
+}
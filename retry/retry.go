@@ -0,0 +1,187 @@
+// Package retry runs operations with exponential backoff, retrying only the
+// failures that errx marks as retryable.
+//
+//	err := retry.Do(ctx, func(ctx context.Context) error {
+//	    return client.Charge(ctx, req)
+//	}, retry.WithMaxAttempts(5), retry.WithCodes(errx.CodeUnavailable, errx.CodeAborted))
+//
+// An error is retried when its code is one of the codes configured with
+// [WithCodes], or when errx.Retryability resolves to RetryableYes. Errors
+// explicitly marked with WithNonRetryable are never retried, whatever their
+// code. A retry delay hint carried by the error takes precedence over the
+// computed backoff. When fn returns an *errx.Error, the number of attempts made
+// is recorded under the "attempts" metadata key of a copy of it, so sentinel
+// errors are never modified. Errors wrapped by other types are returned as fn
+// built them.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/bjaus/errx"
+)
+
+// Default backoff settings.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 100 * time.Millisecond
+	DefaultMaxDelay    = 10 * time.Second
+	DefaultJitter      = 0.2
+)
+
+// Option configures Do.
+type Option func(*config)
+
+type config struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	jitter      float64
+	codes       []errx.Code
+	hint        func(error) (time.Duration, bool)
+}
+
+// WithMaxAttempts sets the maximum number of attempts, including the first.
+// Values below 1 are treated as 1.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = max(n, 1)
+	}
+}
+
+// WithBaseDelay sets the delay before the first retry. Each later retry doubles it.
+func WithBaseDelay(d time.Duration) Option {
+	return func(c *config) {
+		c.baseDelay = d
+	}
+}
+
+// WithMaxDelay caps the computed backoff delay. Delay hints carried by errors are not capped.
+func WithMaxDelay(d time.Duration) Option {
+	return func(c *config) {
+		c.maxDelay = d
+	}
+}
+
+// WithJitter sets the fraction (0 to 1) by which each computed delay is randomly
+// shortened, spreading out retries from concurrent callers.
+func WithJitter(fraction float64) Option {
+	return func(c *config) {
+		c.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithCodes retries errors with any of the given codes in addition to errors
+// that are retryable, overriding the default of their code. Errors explicitly
+// marked non-retryable are still not retried.
+func WithCodes(codes ...errx.Code) Option {
+	return func(c *config) {
+		c.codes = append(c.codes, codes...)
+	}
+}
+
+// WithDelayHint sets the function used to read a server-provided retry delay from
+// an error. Defaults to errx.RetryDelay.
+func WithDelayHint(hint func(error) (time.Duration, bool)) Option {
+	return func(c *config) {
+		c.hint = hint
+	}
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, or the attempt
+// budget is spent, waiting between attempts with exponential backoff.
+//
+// If ctx is done before fn succeeds, Do returns a CodeCanceled or
+// CodeDeadlineExceeded error wrapping the last attempt's error. Otherwise it
+// returns the last error from fn.
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	cfg := config{
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+		jitter:      DefaultJitter,
+		hint:        errx.RetryDelay,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return stopped(err, lastErr, attempt-1)
+		}
+
+		lastErr = fn(ctx)
+		if lastErr == nil {
+			return nil
+		}
+		if attempt >= cfg.maxAttempts || !cfg.shouldRetry(lastErr) {
+			return withAttempts(lastErr, attempt)
+		}
+
+		delay := cfg.delay(attempt, lastErr)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return stopped(context.DeadlineExceeded, lastErr, attempt)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stopped(ctx.Err(), lastErr, attempt)
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether err may succeed on another attempt.
+func (c *config) shouldRetry(err error) bool {
+	switch errx.ExplicitRetryability(err) {
+	case errx.RetryableYes:
+		return true
+	case errx.RetryableNo:
+		return false
+	}
+	return errx.CodeIn(err, c.codes...) || errx.Retryability(err) == errx.RetryableYes
+}
+
+// delay returns how long to wait after the given attempt failed with err.
+func (c *config) delay(attempt int, err error) time.Duration {
+	if d, ok := c.hint(err); ok {
+		return d
+	}
+	d := c.baseDelay << (attempt - 1)
+	if d <= 0 || d > c.maxDelay {
+		d = c.maxDelay
+	}
+	return d - time.Duration(c.jitter*rand.Float64()*float64(d))
+}
+
+// stopped builds the error returned when the context ends the retry loop.
+// ctxErr is the context error that stopped it.
+func stopped(ctxErr, lastErr error, attempts int) error {
+	code, message := errx.CodeCanceled, "retry canceled"
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		code, message = errx.CodeDeadlineExceeded, "retry deadline exceeded"
+	}
+	cause := lastErr
+	if cause == nil {
+		cause = ctxErr
+	}
+	return errx.Wrap(cause, code, message).WithMeta("attempts", attempts)
+}
+
+// withAttempts returns a copy of err with the attempt count recorded, leaving
+// the error returned by fn unchanged. Errors that are not themselves an
+// *errx.Error are returned as is, since annotating the *errx.Error they wrap
+// would drop the wrappers around it.
+func withAttempts(err error, attempts int) error {
+	if _, ok := err.(*errx.Error); !ok {
+		return err
+	}
+	return errx.Annotate(err, errx.WithMeta("attempts", attempts))
+}
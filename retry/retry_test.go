@@ -0,0 +1,300 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+	"github.com/bjaus/errx/retry"
+)
+
+type retrySuite struct {
+	suite.Suite
+}
+
+func TestRetrySuite(t *testing.T) {
+	suite.Run(t, new(retrySuite))
+}
+
+// fast keeps test delays short and deterministic.
+var fast = []retry.Option{
+	retry.WithBaseDelay(time.Millisecond),
+	retry.WithMaxDelay(5 * time.Millisecond),
+	retry.WithJitter(0),
+}
+
+func (s *retrySuite) do(ctx context.Context, fn func(context.Context) error, opts ...retry.Option) error {
+	return retry.Do(ctx, fn, append(fast, opts...)...)
+}
+
+func (s *retrySuite) TestSuccessFirstAttempt() {
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal(1, calls)
+}
+
+func (s *retrySuite) TestRetriesRetryableErrors() {
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errx.NewUnavailable("down").WithRetryable()
+		}
+		return nil
+	}, retry.WithMaxAttempts(5))
+
+	s.NoError(err)
+	s.Equal(3, calls)
+}
+
+func (s *retrySuite) TestStopsOnNonRetryable() {
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		return errx.NewInvalidArgument("bad input")
+	}, retry.WithMaxAttempts(5))
+
+	s.Equal(1, calls)
+	s.True(errx.CodeIs(err, errx.CodeInvalidArgument))
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal(1, e.Metadata()["attempts"])
+}
+
+func (s *retrySuite) TestStopsOnPlainError() {
+	boom := errors.New("boom")
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		return boom
+	})
+
+	s.Equal(1, calls)
+	s.Equal(boom, err)
+}
+
+func (s *retrySuite) TestKeepsWrappedErrors() {
+	sentinel := errors.New("rejected")
+	inner := errx.NewInvalidArgument("bad input")
+	err := s.do(context.Background(), func(context.Context) error {
+		return fmt.Errorf("%w: %w", sentinel, inner)
+	})
+
+	s.ErrorIs(err, sentinel)
+	s.ErrorIs(err, inner)
+	s.Equal("rejected: bad input", err.Error())
+	s.Empty(inner.Metadata())
+
+	wrapped := &wrapError{err: inner}
+	err = s.do(context.Background(), func(context.Context) error { return wrapped })
+
+	var target *wrapError
+	s.Require().ErrorAs(err, &target)
+	s.Same(wrapped, target)
+}
+
+func (s *retrySuite) TestRetriesConfiguredCodes() {
+	conflict := errx.NewAborted("conflict")
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		return conflict
+	}, retry.WithMaxAttempts(4), retry.WithCodes(errx.CodeUnavailable, errx.CodeAborted))
+
+	s.Equal(4, calls)
+	s.True(errx.CodeIs(err, errx.CodeAborted))
+	s.ErrorIs(err, conflict)
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal(4, e.Metadata()["attempts"])
+	s.Empty(conflict.Metadata(), "the error returned by fn is not modified")
+}
+
+func (s *retrySuite) TestConcurrentSentinel() {
+	sentinel := errx.NewUnavailable("down")
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.do(context.Background(), func(context.Context) error {
+				return sentinel
+			}, retry.WithMaxAttempts(2))
+		}()
+	}
+	wg.Wait()
+	s.Empty(sentinel.Metadata())
+}
+
+func (s *retrySuite) TestConfiguredCodesOverrideDefaults() {
+	for _, code := range []errx.Code{errx.CodeInternal, errx.CodeFailedPrecondition} {
+		s.Run(code.String(), func() {
+			calls := 0
+			_ = s.do(context.Background(), func(context.Context) error {
+				calls++
+				return errx.New(code, "not ready")
+			}, retry.WithMaxAttempts(3), retry.WithCodes(code))
+
+			s.Equal(3, calls)
+		})
+	}
+}
+
+func (s *retrySuite) TestRetriesCodeDefaults() {
+	calls := 0
+	err := s.do(context.Background(), func(context.Context) error {
+		calls++
+		return errx.WrapInternal(errx.NewUnavailable("down"), "call failed")
+	})
+
+	s.Equal(3, calls)
+	s.True(errx.CodeIs(err, errx.CodeInternal))
+}
+
+func (s *retrySuite) TestNonRetryableOverridesCodes() {
+	calls := 0
+	_ = s.do(context.Background(), func(context.Context) error {
+		calls++
+		return errx.NewInternal("corrupt").WithNonRetryable()
+	}, retry.WithCodes(errx.CodeInternal))
+
+	s.Equal(1, calls)
+}
+
+func (s *retrySuite) TestMaxAttemptsFloor() {
+	calls := 0
+	_ = s.do(context.Background(), func(context.Context) error {
+		calls++
+		return errx.NewUnavailable("down").WithRetryable()
+	}, retry.WithMaxAttempts(0))
+
+	s.Equal(1, calls)
+}
+
+func (s *retrySuite) TestBackoffGrowsAndCaps() {
+	var stamps []time.Time
+	_ = retry.Do(context.Background(), func(context.Context) error {
+		stamps = append(stamps, time.Now())
+		return errx.NewUnavailable("down").WithRetryable()
+	},
+		retry.WithMaxAttempts(4),
+		retry.WithBaseDelay(10*time.Millisecond),
+		retry.WithMaxDelay(20*time.Millisecond),
+		retry.WithJitter(0),
+	)
+
+	s.Require().Len(stamps, 4)
+	s.GreaterOrEqual(stamps[1].Sub(stamps[0]), 10*time.Millisecond)
+	s.GreaterOrEqual(stamps[2].Sub(stamps[1]), 20*time.Millisecond)
+	s.GreaterOrEqual(stamps[3].Sub(stamps[2]), 20*time.Millisecond)
+}
+
+func (s *retrySuite) TestHonorsDelayHint() {
+	var stamps []time.Time
+	_ = s.do(context.Background(), func(context.Context) error {
+		stamps = append(stamps, time.Now())
+		return errx.NewResourceExhausted("slow down").WithRetryAfter(30 * time.Millisecond)
+	}, retry.WithMaxAttempts(2))
+
+	s.Require().Len(stamps, 2)
+	s.GreaterOrEqual(stamps[1].Sub(stamps[0]), 30*time.Millisecond)
+}
+
+func (s *retrySuite) TestCustomDelayHint() {
+	hinted := 0
+	_ = s.do(context.Background(), func(context.Context) error {
+		return errx.NewUnavailable("down").WithRetryable()
+	},
+		retry.WithMaxAttempts(3),
+		retry.WithDelayHint(func(error) (time.Duration, bool) {
+			hinted++
+			return time.Millisecond, true
+		}),
+	)
+
+	s.Equal(2, hinted)
+}
+
+func (s *retrySuite) TestCanceledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := s.do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return errx.NewUnavailable("down").WithRetryable()
+	}, retry.WithMaxAttempts(5))
+
+	s.Equal(1, calls)
+	s.True(errx.CodeIs(err, errx.CodeCanceled))
+
+	e, ok := errx.As(err)
+	s.Require().True(ok)
+	s.Equal(1, e.Metadata()["attempts"])
+
+	// The last attempt's error is preserved in the chain.
+	cause, ok := errx.As(e.Unwrap())
+	s.Require().True(ok)
+	s.Equal(errx.CodeUnavailable, cause.Code())
+}
+
+func (s *retrySuite) TestCanceledBeforeFirstAttempt() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := s.do(ctx, func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	s.Equal(0, calls)
+	s.True(errx.CodeIs(err, errx.CodeCanceled))
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *retrySuite) TestDeadlineShorterThanDelay() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := s.do(ctx, func(context.Context) error {
+		return errx.NewResourceExhausted("slow down").WithRetryAfter(time.Minute)
+	})
+
+	s.Less(time.Since(start), 50*time.Millisecond, "should give up without waiting for the deadline")
+	s.True(errx.CodeIs(err, errx.CodeDeadlineExceeded))
+}
+
+func (s *retrySuite) TestDeadlineDuringWait() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := retry.Do(ctx, func(context.Context) error {
+		return errx.NewUnavailable("down").WithRetryable()
+	},
+		retry.WithMaxAttempts(100),
+		retry.WithBaseDelay(5*time.Millisecond),
+		retry.WithMaxDelay(5*time.Millisecond),
+	)
+
+	s.True(errx.CodeIs(err, errx.CodeDeadlineExceeded))
+	s.ErrorContains(err, "retry deadline exceeded")
+}
+
+type wrapError struct{ err error }
+
+func (e *wrapError) Error() string { return "wrapped: " + e.err.Error() }
+func (e *wrapError) Unwrap() error { return e.err }
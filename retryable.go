@@ -0,0 +1,72 @@
+package errx
+
+// Retryable is a tri-state retry decision.
+// The zero value, RetryableUnspecified, means no decision was made.
+type Retryable uint8
+
+// Retry decisions.
+const (
+	RetryableUnspecified Retryable = iota // No explicit decision
+	RetryableYes                          // The operation may succeed if retried
+	RetryableNo                           // The operation must not be retried
+)
+
+// String implements the Stringer interface.
+func (r Retryable) String() string {
+	switch r {
+	case RetryableYes:
+		return "yes"
+	case RetryableNo:
+		return "no"
+	default:
+		return "unspecified"
+	}
+}
+
+// Retryability resolves whether err may be retried by walking its whole tree:
+//
+//  1. The nearest *Error with an explicit decision (WithRetryable, WithRetryAfter
+//     or WithNonRetryable) wins.
+//  2. Otherwise, the nearest *Error whose code has a default decision wins.
+//     Unavailable, aborted, resource_exhausted and deadline_exceeded default to
+//     RetryableYes; unknown and internal have no default; all other codes
+//     default to RetryableNo.
+//  3. Otherwise, the result is RetryableUnspecified.
+//
+// This means wrapping a retryable error with WrapInternal keeps it retryable,
+// while an explicit WithNonRetryable anywhere above it overrides it.
+func Retryability(err error) Retryable {
+	if r := ExplicitRetryability(err); r != RetryableUnspecified {
+		return r
+	}
+	for e := range chain(err) {
+		if r := defaultRetryability(e.code); r != RetryableUnspecified {
+			return r
+		}
+	}
+	return RetryableUnspecified
+}
+
+// ExplicitRetryability returns the decision of the nearest *Error in err's tree
+// marked with WithRetryable, WithRetryAfter or WithNonRetryable, ignoring the
+// defaults implied by codes. It returns RetryableUnspecified if there is none.
+func ExplicitRetryability(err error) Retryable {
+	for e := range chain(err) {
+		if e.retryable != RetryableUnspecified {
+			return e.retryable
+		}
+	}
+	return RetryableUnspecified
+}
+
+// defaultRetryability returns the retry decision implied by code alone.
+func defaultRetryability(code Code) Retryable {
+	switch code {
+	case CodeUnavailable, CodeAborted, CodeResourceExhausted, CodeDeadlineExceeded:
+		return RetryableYes
+	case CodeUnknown, CodeInternal:
+		return RetryableUnspecified
+	default:
+		return RetryableNo
+	}
+}
@@ -0,0 +1,126 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type retryableSuite struct {
+	suite.Suite
+}
+
+func TestRetryableSuite(t *testing.T) {
+	suite.Run(t, new(retryableSuite))
+}
+
+func (s *retryableSuite) TestString() {
+	s.Equal("unspecified", errx.RetryableUnspecified.String())
+	s.Equal("yes", errx.RetryableYes.String())
+	s.Equal("no", errx.RetryableNo.String())
+}
+
+func (s *retryableSuite) TestCodeDefaults() {
+	tests := map[errx.Code]errx.Retryable{
+		errx.CodeUnknown:            errx.RetryableUnspecified,
+		errx.CodeCanceled:           errx.RetryableNo,
+		errx.CodeInvalidArgument:    errx.RetryableNo,
+		errx.CodeDeadlineExceeded:   errx.RetryableYes,
+		errx.CodeNotFound:           errx.RetryableNo,
+		errx.CodeAlreadyExists:      errx.RetryableNo,
+		errx.CodePermissionDenied:   errx.RetryableNo,
+		errx.CodeResourceExhausted:  errx.RetryableYes,
+		errx.CodeFailedPrecondition: errx.RetryableNo,
+		errx.CodeAborted:            errx.RetryableYes,
+		errx.CodeOutOfRange:         errx.RetryableNo,
+		errx.CodeUnimplemented:      errx.RetryableNo,
+		errx.CodeInternal:           errx.RetryableUnspecified,
+		errx.CodeUnavailable:        errx.RetryableYes,
+		errx.CodeDataLoss:           errx.RetryableNo,
+		errx.CodeUnauthenticated:    errx.RetryableNo,
+	}
+
+	s.Len(tests, len(errx.CodeValues()))
+	for code, want := range tests {
+		s.Run(code.String(), func() {
+			s.Equal(want, errx.Retryability(errx.New(code, "test")))
+		})
+	}
+}
+
+func (s *retryableSuite) TestRetryability() {
+	unavailable := func() *errx.Error { return errx.NewUnavailable("down") }
+
+	tests := map[string]struct {
+		err  error
+		want errx.Retryable
+	}{
+		"nil":                        {err: nil, want: errx.RetryableUnspecified},
+		"plain error":                {err: errors.New("boom"), want: errx.RetryableUnspecified},
+		"explicit yes":               {err: errx.NewInternal("x").WithRetryable(), want: errx.RetryableYes},
+		"explicit no":                {err: unavailable().WithNonRetryable(), want: errx.RetryableNo},
+		"retry after implies yes":    {err: errx.NewInternal("x").WithRetryAfter(time.Second), want: errx.RetryableYes},
+		"wrapped by internal":        {err: errx.WrapInternal(unavailable().WithRetryable(), "failed"), want: errx.RetryableYes},
+		"wrapped by fmt":             {err: fmt.Errorf("ctx: %w", unavailable()), want: errx.RetryableYes},
+		"code default through wrap":  {err: errx.WrapInternal(unavailable(), "failed"), want: errx.RetryableYes},
+		"nearest code default":       {err: errx.WrapInvalidArgument(unavailable(), "bad"), want: errx.RetryableNo},
+		"explicit beats code":        {err: errx.WrapInvalidArgument(unavailable().WithRetryable(), "bad"), want: errx.RetryableYes},
+		"outer explicit no wins":     {err: errx.WrapInternal(unavailable().WithRetryable(), "x").WithNonRetryable(), want: errx.RetryableNo},
+		"nearest explicit wins":      {err: errx.WrapInternal(errx.NewInternal("x").WithNonRetryable(), "y").WithRetryable(), want: errx.RetryableYes},
+		"joined errors":              {err: errors.Join(errors.New("a"), unavailable().WithRetryable()), want: errx.RetryableYes},
+		"no decision in whole chain": {err: errx.WrapInternal(errx.NewUnknown("x"), "y"), want: errx.RetryableUnspecified},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Equal(tt.want, errx.Retryability(tt.err))
+			s.Equal(tt.want == errx.RetryableYes, errx.IsRetryable(tt.err))
+		})
+	}
+}
+
+func (s *retryableSuite) TestExplicitRetryability() {
+	s.Equal(errx.RetryableUnspecified, errx.ExplicitRetryability(nil))
+	s.Equal(errx.RetryableUnspecified, errx.ExplicitRetryability(errx.NewUnavailable("down")))
+	s.Equal(errx.RetryableNo, errx.ExplicitRetryability(errx.WrapInternal(errx.NewUnavailable("down").WithNonRetryable(), "failed")))
+	s.Equal(errx.RetryableYes, errx.ExplicitRetryability(fmt.Errorf("x: %w", errx.NewInternal("boom").WithRetryable())))
+}
+
+func (s *retryableSuite) TestNonRetryableClearsRetryAfter() {
+	err := errx.NewResourceExhausted("quota").WithRetryAfter(time.Minute).WithNonRetryable()
+
+	_, ok := err.RetryAfter()
+	s.False(ok)
+	s.False(err.IsRetryable())
+}
+
+func (s *retryableSuite) TestNonRetryableHidesInnerRetryDelay() {
+	inner := errx.NewResourceExhausted("quota").WithRetryAfter(time.Minute)
+	outer := errx.WrapInternal(inner, "failed").WithNonRetryable()
+
+	_, ok := errx.RetryDelay(outer)
+	s.False(ok)
+}
+
+func (s *retryableSuite) TestDebugMessageAndLogValue() {
+	err := errx.NewUnavailable("down").WithNonRetryable()
+	s.Contains(err.DebugMessage(), "retryable=false")
+
+	attrs := err.LogValue().Group()
+	found := false
+	for _, a := range attrs {
+		if a.Key == "retryable" {
+			found = true
+			s.False(a.Value.Bool())
+		}
+	}
+	s.True(found)
+
+	// Code defaults are not rendered as explicit decisions
+	s.NotContains(errx.NewUnavailable("down").DebugMessage(), "retryable")
+}
@@ -0,0 +1,190 @@
+package errx
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Severity ranks how serious an error is for the service that observes it.
+// The zero value, SeverityUnspecified, means no decision was made.
+type Severity uint8
+
+// Severities, from least to most serious.
+const (
+	SeverityUnspecified Severity = iota // No explicit decision
+	SeverityInfo                        // Expected outcome, such as a request for a missing resource
+	SeverityWarning                     // Worth watching in aggregate, such as denied access or exhausted quota
+	SeverityError                       // The service failed
+	SeverityCritical                    // The service failed and lost or corrupted data
+)
+
+// String implements the Stringer interface.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unspecified"
+	}
+}
+
+// Level returns the slog level for the severity. SeverityCritical maps to
+// slog.LevelError+4 and SeverityUnspecified to slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelError
+	}
+}
+
+// defaultSeverities is the built-in severity of each code. Codes that describe
+// a caller's mistake are info or warning, so they stay off error dashboards;
+// codes that describe a failure of the service are errors.
+var defaultSeverities = map[Code]Severity{
+	CodeUnknown:            SeverityError,
+	CodeCanceled:           SeverityInfo,
+	CodeInvalidArgument:    SeverityInfo,
+	CodeDeadlineExceeded:   SeverityWarning,
+	CodeNotFound:           SeverityInfo,
+	CodeAlreadyExists:      SeverityInfo,
+	CodePermissionDenied:   SeverityWarning,
+	CodeResourceExhausted:  SeverityWarning,
+	CodeFailedPrecondition: SeverityInfo,
+	CodeAborted:            SeverityWarning,
+	CodeOutOfRange:         SeverityInfo,
+	CodeUnimplemented:      SeverityError,
+	CodeInternal:           SeverityError,
+	CodeUnavailable:        SeverityError,
+	CodeDataLoss:           SeverityCritical,
+	CodeUnauthenticated:    SeverityWarning,
+}
+
+var severities atomic.Pointer[map[Code]Severity]
+
+func init() {
+	severities.Store(&defaultSeverities)
+}
+
+// DefaultSeverities returns a copy of the built-in severity table:
+//
+//	info:     canceled, invalid_argument, not_found, already_exists,
+//	          failed_precondition, out_of_range
+//	warning:  deadline_exceeded, permission_denied, resource_exhausted,
+//	          aborted, unauthenticated
+//	error:    unknown, unimplemented, internal, unavailable
+//	critical: data_loss
+//
+// Modify the copy and pass it to SetSeverities to change some entries.
+func DefaultSeverities() map[Code]Severity {
+	return maps.Clone(defaultSeverities)
+}
+
+// SetSeverities sets the severity of each code. Codes missing from table have
+// SeverityError. Passing nil restores DefaultSeverities.
+func SetSeverities(table map[Code]Severity) {
+	if table == nil {
+		severities.Store(&defaultSeverities)
+		return
+	}
+	table = maps.Clone(table)
+	severities.Store(&table)
+}
+
+// Severity returns the severity of the code in the table set with SetSeverities.
+func (c Code) Severity() Severity {
+	if s, ok := (*severities.Load())[c]; ok && s != SeverityUnspecified {
+		return s
+	}
+	return SeverityError
+}
+
+// WithSeverity overrides the severity implied by the error's code, for example
+// to log an expected internal failure as a warning. Passing SeverityUnspecified
+// removes the override.
+func (e *Error) WithSeverity(s Severity) *Error {
+	if e == nil {
+		return nil
+	}
+	e.severity = s
+	return e
+}
+
+// Severity returns the error's severity, resolved over the error and its causes
+// as described by [SeverityOf].
+func (e *Error) Severity() Severity {
+	if e == nil {
+		return SeverityUnspecified
+	}
+	return SeverityOf(e)
+}
+
+// SeverityOf resolves the severity of err:
+//
+//  1. The nearest *Error with a severity set by WithSeverity wins.
+//  2. Otherwise, the severity of the nearest *Error's code, as with CodeOf.
+//  3. Otherwise, SeverityError: failures errx knows nothing about are errors.
+//
+// A nil error has SeverityUnspecified.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return SeverityUnspecified
+	}
+	for e := range chain(err) {
+		if e.severity != SeverityUnspecified {
+			return e.severity
+		}
+	}
+	return CodeOf(err).Severity()
+}
+
+// Level returns the slog level at which err should be logged, so expected
+// client errors log at Info or Warn while internal failures log at Error:
+//
+//	logger.Log(ctx, errx.Level(err), "request failed", "error", err)
+//
+// A nil error logs at slog.LevelInfo.
+func Level(err error) slog.Level {
+	if err == nil {
+		return slog.LevelInfo
+	}
+	return SeverityOf(err).Level()
+}
+
+// Log logs err with msg at Level(err), under the "error" key, followed by
+// args. A nil logger uses slog.Default. The record's source is the caller of
+// Log, as if it had called logger.Log itself. Log runs the OnCheck hooks on err
+// even if the level is disabled.
+func Log(ctx context.Context, logger *slog.Logger, msg string, err error, args ...any) {
+	Check(err)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := Level(err)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip runtime.Callers and Log
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(append([]any{slog.Any("error", err)}, args...)...)
+	_ = logger.Handler().Handle(ctx, r)
+}
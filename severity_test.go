@@ -0,0 +1,180 @@
+package errx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type severitySuite struct {
+	suite.Suite
+}
+
+func TestSeveritySuite(t *testing.T) {
+	suite.Run(t, new(severitySuite))
+}
+
+func (s *severitySuite) TearDownTest() {
+	errx.SetSeverities(nil)
+}
+
+func (s *severitySuite) TestString() {
+	s.Equal("unspecified", errx.SeverityUnspecified.String())
+	s.Equal("info", errx.SeverityInfo.String())
+	s.Equal("warning", errx.SeverityWarning.String())
+	s.Equal("error", errx.SeverityError.String())
+	s.Equal("critical", errx.SeverityCritical.String())
+}
+
+func (s *severitySuite) TestLevel() {
+	s.Equal(slog.LevelInfo, errx.SeverityInfo.Level())
+	s.Equal(slog.LevelWarn, errx.SeverityWarning.Level())
+	s.Equal(slog.LevelError, errx.SeverityError.Level())
+	s.Equal(slog.LevelError+4, errx.SeverityCritical.Level())
+	s.Equal(slog.LevelError, errx.SeverityUnspecified.Level())
+}
+
+func (s *severitySuite) TestCodeDefaults() {
+	tests := map[errx.Code]errx.Severity{
+		errx.CodeUnknown:            errx.SeverityError,
+		errx.CodeCanceled:           errx.SeverityInfo,
+		errx.CodeInvalidArgument:    errx.SeverityInfo,
+		errx.CodeDeadlineExceeded:   errx.SeverityWarning,
+		errx.CodeNotFound:           errx.SeverityInfo,
+		errx.CodeAlreadyExists:      errx.SeverityInfo,
+		errx.CodePermissionDenied:   errx.SeverityWarning,
+		errx.CodeResourceExhausted:  errx.SeverityWarning,
+		errx.CodeFailedPrecondition: errx.SeverityInfo,
+		errx.CodeAborted:            errx.SeverityWarning,
+		errx.CodeOutOfRange:         errx.SeverityInfo,
+		errx.CodeUnimplemented:      errx.SeverityError,
+		errx.CodeInternal:           errx.SeverityError,
+		errx.CodeUnavailable:        errx.SeverityError,
+		errx.CodeDataLoss:           errx.SeverityCritical,
+		errx.CodeUnauthenticated:    errx.SeverityWarning,
+	}
+
+	s.Len(tests, len(errx.CodeValues()))
+	s.Equal(tests, errx.DefaultSeverities())
+	for code, want := range tests {
+		s.Run(code.String(), func() {
+			s.Equal(want, code.Severity())
+		})
+	}
+}
+
+func (s *severitySuite) TestSetSeverities() {
+	table := errx.DefaultSeverities()
+	table[errx.CodeNotFound] = errx.SeverityWarning
+	delete(table, errx.CodeCanceled)
+	errx.SetSeverities(table)
+
+	// Later changes to the table have no effect.
+	table[errx.CodeNotFound] = errx.SeverityCritical
+
+	s.Equal(errx.SeverityWarning, errx.CodeNotFound.Severity())
+	s.Equal(errx.SeverityError, errx.CodeCanceled.Severity())
+	s.Equal(errx.SeverityInfo, errx.DefaultSeverities()[errx.CodeNotFound])
+
+	errx.SetSeverities(nil)
+	s.Equal(errx.SeverityInfo, errx.CodeNotFound.Severity())
+}
+
+func (s *severitySuite) TestSeverityOf() {
+	tests := map[string]struct {
+		err  error
+		want errx.Severity
+	}{
+		"nil":        {err: nil, want: errx.SeverityUnspecified},
+		"plain":      {err: errors.New("boom"), want: errx.SeverityError},
+		"code":       {err: errx.NewNotFound("missing"), want: errx.SeverityInfo},
+		"override":   {err: errx.NewInternal("expected").WithSeverity(errx.SeverityWarning), want: errx.SeverityWarning},
+		"fmt wrap":   {err: fmt.Errorf("load: %w", errx.NewInvalidArgument("bad")), want: errx.SeverityInfo},
+		"outer code": {err: errx.WrapInternal(errx.NewNotFound("missing"), "lookup failed"), want: errx.SeverityError},
+		"inner override": {
+			err:  errx.WrapInternal(errx.NewNotFound("missing").WithSeverity(errx.SeverityCritical), "lookup failed"),
+			want: errx.SeverityCritical,
+		},
+		"outer override wins": {
+			err:  errx.WrapInternal(errx.NewNotFound("missing").WithSeverity(errx.SeverityCritical), "lookup failed").WithSeverity(errx.SeverityInfo),
+			want: errx.SeverityInfo,
+		},
+		"cleared override": {
+			err:  errx.NewInternal("boom").WithSeverity(errx.SeverityInfo).WithSeverity(errx.SeverityUnspecified),
+			want: errx.SeverityError,
+		},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Equal(tt.want, errx.SeverityOf(tt.err))
+		})
+	}
+}
+
+func (s *severitySuite) TestErrorSeverity() {
+	var nilErr *errx.Error
+	s.Equal(errx.SeverityUnspecified, nilErr.Severity())
+	s.Nil(nilErr.WithSeverity(errx.SeverityInfo))
+	s.Equal(errx.SeverityCritical, errx.NewDataLoss("lost").Severity())
+}
+
+func (s *severitySuite) TestLevelOf() {
+	s.Equal(slog.LevelInfo, errx.Level(nil))
+	s.Equal(slog.LevelInfo, errx.Level(errx.NewNotFound("missing")))
+	s.Equal(slog.LevelWarn, errx.Level(errx.NewUnauthenticated("who?")))
+	s.Equal(slog.LevelError, errx.Level(errx.NewInternal("boom")))
+	s.Equal(slog.LevelError, errx.Level(errors.New("boom")))
+}
+
+func (s *severitySuite) TestLog() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	errx.Log(context.Background(), logger, "lookup failed", errx.NewNotFound("missing"))
+	s.Empty(buf.String())
+
+	errx.Log(context.Background(), logger, "save failed", errors.New("disk full"), "order", 7)
+	s.Equal("level=ERROR msg=\"save failed\" error=\"disk full\" order=7\n", buf.String())
+}
+
+func (s *severitySuite) TestLogSource() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true}))
+	errx.Log(context.Background(), logger, "save failed", errx.NewInternal("boom"))
+
+	var entry struct {
+		Source slog.Source `json:"source"`
+	}
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("severity_test.go", filepath.Base(entry.Source.File))
+	s.Contains(entry.Source.Function, "TestLogSource")
+}
+
+func (s *severitySuite) TestRendered() {
+	err := errx.NewInternal("expected").WithSeverity(errx.SeverityWarning)
+	s.Contains(err.DebugMessage(), "severity=warning")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("x", "error", err)
+	s.Contains(buf.String(), `"severity":"warning"`)
+
+	s.NotContains(errx.NewInternal("boom").DebugMessage(), "severity=")
+}
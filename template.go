@@ -0,0 +1,102 @@
+package errx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// placeholderPattern matches {name} placeholders in message templates.
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// NewTemplate creates a new Error whose message is a template. Placeholders such
+// as {user_id} are replaced with the detail of that name when the message is
+// rendered by Error, so the template stays constant while details carry the
+// variable data:
+//
+//	errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").WithDetail("user_id", id)
+//
+// Braces that do not enclose an identifier are kept literally. The template must
+// be safe to expose to clients once rendered.
+func NewTemplate(code Code, template string) *Error {
+	e := newError(code, template, nil)
+	e.templated = true
+	return e
+}
+
+// WrapTemplate wraps an existing error with a message template; see [NewTemplate].
+// Returns nil if err is nil.
+func WrapTemplate(err error, code Code, template string) *Error {
+	if err == nil {
+		return nil
+	}
+	e := newError(code, template, err)
+	e.templated = true
+	return e
+}
+
+// Template returns the unrendered message template of an error created with
+// NewTemplate or WrapTemplate, or "" for other errors.
+func (e *Error) Template() string {
+	if e == nil || !e.templated {
+		return ""
+	}
+	return e.message
+}
+
+// CheckTemplate reports placeholders in the error's message template that have no
+// detail. It returns nil for errors without a template.
+func (e *Error) CheckTemplate() error {
+	if e == nil || !e.templated {
+		return nil
+	}
+	return ValidateTemplate(e.message, e.details)
+}
+
+// Placeholders returns the names of the {name} placeholders in template, in order
+// of first appearance.
+func Placeholders(template string) []string {
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// ValidateTemplate reports placeholders in template without a value in details.
+// Call it from tests, or at package initialization for templates declared as
+// variables, to catch mismatches before they reach clients:
+//
+//	func TestTemplates(t *testing.T) {
+//	    err := errx.ValidateTemplate(msgUserNotFound, map[string]any{"user_id": ""})
+//	    require.NoError(t, err)
+//	}
+func ValidateTemplate(template string, details map[string]any) error {
+	var missing []string
+	for _, name := range Placeholders(template) {
+		if _, ok := details[name]; !ok {
+			missing = append(missing, "{"+name+"}")
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("template %q: no detail for %s", template, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Interpolate replaces {name} placeholders in template with the matching values,
+// formatted with fmt.Sprint. Placeholders without a value are left as is.
+func Interpolate(template string, values map[string]any) string {
+	if len(values) == 0 || !strings.Contains(template, "{") {
+		return template
+	}
+	return placeholderPattern.ReplaceAllStringFunc(template, func(m string) string {
+		if v, ok := values[m[1:len(m)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		return m
+	})
+}
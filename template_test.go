@@ -0,0 +1,97 @@
+package errx_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/bjaus/errx"
+)
+
+type templateSuite struct {
+	suite.Suite
+}
+
+func TestTemplateSuite(t *testing.T) {
+	suite.Run(t, new(templateSuite))
+}
+
+func (s *templateSuite) TestNewTemplate() {
+	err := errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found in {org}").WithDetail("user_id", "u-1")
+
+	s.Equal("user u-1 not found in {org}", err.Error(), "missing details leave the placeholder")
+	s.Equal("user {user_id} not found in {org}", err.Template())
+
+	err.WithDetail("org", "acme")
+	s.Equal("user u-1 not found in acme", err.Error(), "rendered when the message is read")
+	s.Contains(err.FormatStackTrace(), "TestNewTemplate")
+}
+
+func (s *templateSuite) TestWrapTemplate() {
+	cause := errors.New("no rows")
+	err := errx.WrapTemplate(cause, errx.CodeNotFound, "user {user_id} not found").WithDetail("user_id", 7)
+
+	s.ErrorIs(err, cause)
+	s.Equal("user 7 not found", err.Error())
+	s.Nil(errx.WrapTemplate(nil, errx.CodeNotFound, "x"))
+}
+
+func (s *templateSuite) TestPlainMessagesAreNotTemplates() {
+	err := errx.New(errx.CodeInvalidArgument, "expected {json}").WithDetail("json", "x")
+	s.Equal("expected {json}", err.Error())
+	s.Empty(err.Template())
+	s.NoError(err.CheckTemplate())
+}
+
+func (s *templateSuite) TestLiteralBraces() {
+	err := errx.NewTemplate(errx.CodeInvalidArgument, "body must be {} or {1} not {id}").WithDetail("id", 3)
+	s.Equal("body must be {} or {1} not 3", err.Error())
+}
+
+func (s *templateSuite) TestCheckTemplate() {
+	err := errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found in {org}").WithDetail("user_id", "u-1")
+	s.EqualError(err.CheckTemplate(), `template "user {user_id} not found in {org}": no detail for {org}`)
+
+	var nilErr *errx.Error
+	s.NoError(nilErr.CheckTemplate())
+	s.Empty(nilErr.Template())
+}
+
+func (s *templateSuite) TestValidateTemplate() {
+	s.NoError(errx.ValidateTemplate("user {user_id}", map[string]any{"user_id": nil}))
+	s.NoError(errx.ValidateTemplate("no placeholders", nil))
+	s.EqualError(errx.ValidateTemplate("{a} {b} {a}", nil), `template "{a} {b} {a}": no detail for {a}, {b}`)
+}
+
+func (s *templateSuite) TestPlaceholders() {
+	s.Equal([]string{"a", "b.c"}, errx.Placeholders("{a} {b.c} {a} {} {1x}"))
+	s.Nil(errx.Placeholders("none"))
+}
+
+func (s *templateSuite) TestInterpolate() {
+	s.Equal("1 and {b}", errx.Interpolate("{a} and {b}", map[string]any{"a": 1}))
+	s.Equal("{a}", errx.Interpolate("{a}", nil))
+}
+
+func (s *templateSuite) TestDebugMessageAndLogValue() {
+	err := errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").WithDetail("user_id", "u-1")
+	s.Contains(err.DebugMessage(), "[not_found] user u-1 not found")
+
+	attrs := err.LogValue().Group()
+	s.Contains(attrs, slog.String("message", "user u-1 not found"))
+	s.Contains(attrs, slog.String("template", "user {user_id} not found"))
+}
+
+func (s *templateSuite) TestLocalizedMessageUsesDetails() {
+	errx.SetLocalizer(localizerFunc(func(_, _ string, params map[string]any) (string, bool) {
+		return errx.Interpolate("Benutzer {user_id} nicht gefunden", params), true
+	}))
+	defer errx.SetLocalizer(nil)
+
+	err := errx.NewTemplate(errx.CodeNotFound, "user {user_id} not found").
+		WithDetail("user_id", "u-1").
+		WithMessageKey("user.not_found", nil)
+	s.Equal("Benutzer u-1 nicht gefunden", err.LocalizedMessage("de"))
+}